@@ -2,6 +2,11 @@ package main
 
 import (
 	"go/ast"
+	"go/types"
+	"orm_framework/orm/model"
+	"reflect"
+	"strconv"
+	"strings"
 )
 
 type SingleFileEntryVisitor struct {
@@ -12,8 +17,9 @@ func (s *SingleFileEntryVisitor) Get() *File {
 	types := make([]Type, 0, len(s.file.types))
 	for _, typ := range s.file.types {
 		types = append(types, Type{
-			Name:   typ.name,
-			Fields: typ.fields,
+			Name:     typ.name,
+			Fields:   typ.fields,
+			Embedded: typ.embedded,
 		})
 	}
 	return &File{
@@ -67,6 +73,8 @@ func (f *FileVisitor) Visit(node ast.Node) (w ast.Visitor) {
 type TypeVisitor struct {
 	name   string
 	fields []Field
+	// embedded 是否有嵌入字段
+	embedded bool
 }
 
 func (t *TypeVisitor) Visit(node ast.Node) (w ast.Visitor) {
@@ -74,6 +82,21 @@ func (t *TypeVisitor) Visit(node ast.Node) (w ast.Visitor) {
 	if !ok {
 		return t
 	}
+	if len(n.Names) == 0 {
+		t.embedded = true
+		return t
+	}
+	var ormTag string
+	if n.Tag != nil {
+		tag, err := strconv.Unquote(n.Tag.Value)
+		if err == nil {
+			ormTag = reflect.StructTag(tag).Get("orm")
+		}
+	}
+	// 和 orm 保持一致，忽略的字段不映射到列
+	if ormTag == "-" {
+		return t
+	}
 	var typ string
 	switch nt := n.Type.(type) {
 	case *ast.Ident:
@@ -85,15 +108,21 @@ func (t *TypeVisitor) Visit(node ast.Node) (w ast.Visitor) {
 		case *ast.SelectorExpr:
 			typ = "*" + xt.X.(*ast.Ident).String() + "." + xt.Sel.String()
 		}
+	case *ast.SelectorExpr:
+		typ = nt.X.(*ast.Ident).String() + "." + nt.Sel.String()
 	case *ast.ArrayType:
-		typ = "[]byte"
+		typ = types.ExprString(nt)
 	default:
 		panic("不支持的类型")
 	}
 	for _, name := range n.Names {
+		if !name.IsExported() {
+			continue
+		}
 		t.fields = append(t.fields, Field{
-			Name: name.String(),
-			Type: typ,
+			Name:   name.String(),
+			Type:   typ,
+			Column: column(name.String(), ormTag),
 		})
 	}
 	return t
@@ -102,9 +131,29 @@ func (t *TypeVisitor) Visit(node ast.Node) (w ast.Visitor) {
 type Type struct {
 	Name   string
 	Fields []Field
+	// Embedded 有嵌入字段的类型不生成 Value
+	Embedded bool
 }
 
 type Field struct {
 	Name string
 	Type string
+	// Column 按照默认的命名策略以及标签得到的列名
+	// 有 Serializer 的字段为空，读取的时候交给 orm 处理
+	Column string
+}
+
+// column 解析 orm 标签得到字段的列名，eg: orm:"column=user_name"
+func column(name string, tag string) string {
+	col := model.UnderscoreNaming.ColumnName(name)
+	for _, pair := range strings.Split(tag, ",") {
+		key, val, _ := strings.Cut(strings.TrimSpace(pair), "=")
+		switch key {
+		case "column":
+			col = val
+		case "serializer":
+			return ""
+		}
+	}
+	return col
 }
@@ -1,17 +1,64 @@
 package main
 
 import (
+	"bytes"
 	_ "embed"
+	"flag"
 	"go/ast"
+	"go/format"
 	"go/parser"
 	"go/token"
 	"io"
+	"log"
+	"os"
+	"strings"
 	"text/template"
 )
 
 //go:embed tpl.gohtml
 var genOrm string
 
+//go:embed valuer.gohtml
+var genValuerTpl string
+
+// eg: orm_gen -src user.go
+// 生成 user.gen.go 以及 user_valuer.gen.go
+func main() {
+	src := flag.String("src", "", "需要生成代码的 Go 文件")
+	dst := flag.String("dst", "", "谓词的输出文件，默认为 xxx.gen.go")
+	valuerDst := flag.String("valuer", "", "Value 实现的输出文件，默认为 xxx_valuer.gen.go")
+	flag.Parse()
+	if *src == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	base := strings.TrimSuffix(*src, ".go")
+	if *dst == "" {
+		*dst = base + ".gen.go"
+	}
+	if *valuerDst == "" {
+		*valuerDst = base + "_valuer.gen.go"
+	}
+	if err := genFile(*dst, *src, gen); err != nil {
+		log.Fatal(err)
+	}
+	if err := genFile(*valuerDst, *src, genValuer); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// genFile 使用 fn 为 src 生成代码，写入到 dst
+func genFile(dst string, src string, fn func(w io.Writer, srcFile string) error) error {
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	return fn(f, src)
+}
+
 // gen 调用这个方法来生成代码
 func gen(w io.Writer, srcFile string) error {
 	fset := token.NewFileSet()
@@ -27,12 +74,42 @@ func gen(w io.Writer, srcFile string) error {
 	if err != nil {
 		return err
 	}
-	return tpl.Execute(w, Data{
+	return execute(w, tpl, Data{
 		File: file,
-		Ops:  []string{"LT", "GT", "EQ"},
+		Ops:  []string{"LT", "GT", "Eq"},
 	})
 }
 
+// genValuer 为文件中的结构体生成 orm.Valuer 的实现，有嵌入字段的结构体不生成
+func genValuer(w io.Writer, srcFile string) error {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, srcFile, nil, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	s := &SingleFileEntryVisitor{}
+	ast.Walk(s, f)
+	tpl, err := template.New("gen-valuer").Parse(genValuerTpl)
+	if err != nil {
+		return err
+	}
+	return execute(w, tpl, s.Get())
+}
+
+// execute 渲染模板，格式化之后写入 w
+func execute(w io.Writer, tpl *template.Template, data any) error {
+	buffer := &bytes.Buffer{}
+	if err := tpl.Execute(buffer, data); err != nil {
+		return err
+	}
+	src, err := format.Source(buffer.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
 type Data struct {
 	*File
 	Ops []string
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"io"
 	"os"
 	"testing"
 
@@ -29,3 +30,52 @@ func Test_genFile(t *testing.T) {
 	err = gen(f, "testdata/user.go")
 	require.NoError(t, err)
 }
+
+// gentest 中 go generate 生成的代码需要和当前的模板保持一致
+func Test_genGentest(t *testing.T) {
+	testCases := []struct {
+		name string
+		gen  func(w io.Writer, srcFile string) error
+		dst  string
+	}{
+		{
+			name: "predicate",
+			gen:  gen,
+			dst:  "../../orm/internal/gentest/user.gen.go",
+		},
+		{
+			name: "valuer",
+			gen:  genValuer,
+			dst:  "../../orm/internal/gentest/user_valuer.gen.go",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buffer := &bytes.Buffer{}
+			err := tc.gen(buffer, "../../orm/internal/gentest/user.go")
+			require.NoError(t, err)
+			want, err := os.ReadFile(tc.dst)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), buffer.String())
+		})
+	}
+}
+
+func Test_column(t *testing.T) {
+	testCases := []struct {
+		name    string
+		field   string
+		tag     string
+		wantCol string
+	}{
+		{name: "default", field: "NickName", wantCol: "nick_name"},
+		{name: "column", field: "NickName", tag: "column=nick,size=64", wantCol: "nick"},
+		// 有 Serializer 的字段交给 orm 处理
+		{name: "serializer", field: "Tags", tag: "serializer=comma", wantCol: ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantCol, column(tc.field, tc.tag))
+		})
+	}
+}
@@ -21,7 +21,7 @@ func Avg(c string) Aggregate {
 
 func (a Aggregate) As(alias string) Aggregate {
 	return Aggregate{
-		fn:    "AVG",
+		fn:    a.fn,
 		arg:   a.arg,
 		alias: alias,
 	}
@@ -56,9 +56,45 @@ func Sum(c string) Aggregate {
 }
 
 func (a Aggregate) Eq(val any) Predicate {
-	return Predicate{
-		left:  a,
-		op:    opEQ,
-		right: exprOf(val),
-	}
+	return binary(a, opEQ, val)
+}
+
+func (a Aggregate) NotEq(val any) Predicate {
+	return binary(a, opNEQ, val)
+}
+
+func (a Aggregate) LT(val any) Predicate {
+	return binary(a, opLT, val)
+}
+
+func (a Aggregate) LTEq(val any) Predicate {
+	return binary(a, opLTEQ, val)
+}
+
+func (a Aggregate) GT(val any) Predicate {
+	return binary(a, opGT, val)
+}
+
+func (a Aggregate) GTEq(val any) Predicate {
+	return binary(a, opGTEQ, val)
+}
+
+func (a Aggregate) In(vals ...any) Predicate {
+	return in(a, opIN, vals)
+}
+
+func (a Aggregate) NotIn(vals ...any) Predicate {
+	return in(a, opNOTIN, vals)
+}
+
+func (a Aggregate) Between(start any, end any) Predicate {
+	return between(a, start, end)
+}
+
+func (a Aggregate) IsNull() Predicate {
+	return unary(a, opISNULL)
+}
+
+func (a Aggregate) IsNotNull() Predicate {
+	return unary(a, opISNOTNULL)
 }
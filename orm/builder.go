@@ -4,14 +4,60 @@ package orm
 import (
 	"github.com/valyala/bytebufferpool"
 	"orm_framework/orm/internal/errs"
+	"orm_framework/orm/model"
 )
 
+// Builder 暴露给方言使用的构建能力
+// 第三方方言通过它来写入 SQL 和参数
+type Builder interface {
+	WriteString(str string) (int, error)
+	WriteByte(c byte) error
+	// Quote 按照方言引用标识符
+	Quote(name string)
+	AddArgs(args ...any)
+	// Model 当前语句的模型
+	Model() *model.Model
+	// ColName 返回字段对应的列名
+	ColName(goName string) (string, error)
+	// BuildColumn 按照当前模型构建字段对应的列
+	BuildColumn(goName string) error
+	BuildExpression(e Expression) error
+	// BuildAssignment 构建 col=val
+	BuildAssignment(a Assignment) error
+}
+
+// SelectBuilder SELECT 语句的构建能力
+type SelectBuilder interface {
+	Builder
+	// HasOrderBy 是否已经构建了 ORDER BY
+	HasOrderBy() bool
+}
+
+var _ Builder = &builder{}
+
 type builder struct {
 	core
 
 	buffer *bytebufferpool.ByteBuffer
 	args   []any
-	quoter byte
+}
+
+// reset 重置构建状态
+// Build 可能会被中间件多次调用，子查询也会随着外层查询重复构建
+// 所以每次都需要使用新的 buffer 并清空参数
+func (b *builder) reset() {
+	b.buffer = bytebufferpool.Get()
+	b.args = nil
+}
+
+// end 结束构建
+// 部分方言需要改写占位符，例如 PostgreSQL 使用 $1
+func (b *builder) end() *Query {
+	b.writeByte(';')
+	return &Query{
+		SQL:  b.dialect.BindVars(b.buffer.String()),
+		Args: b.args,
+	}
 }
 
 func (b *builder) writeString(str string) {
@@ -25,25 +71,31 @@ func (b *builder) writeByte(c byte) {
 func (b *builder) buildColumn(c *Column) error {
 	switch table := c.table.(type) {
 	case nil:
-		field, ok := b.model.FieldMap[c.column]
-		if !ok {
-			return errs.NewErrUnknownField(c.column)
+		colName, err := b.colName(nil, c.column)
+		if err != nil {
+			return err
 		}
-		b.quote(field.ColName)
+		b.quote(colName)
 	case Table:
-		m, err := b.r.Get(table.entity)
+		colName, err := b.colName(table, c.column)
 		if err != nil {
 			return err
 		}
-		field, ok := m.FieldMap[c.column]
-		if !ok {
-			return errs.NewErrUnknownField(c.column)
+		if table.alias != "" {
+			b.quote(table.alias)
+			b.writeByte('.')
+		}
+		b.quote(colName)
+	case Subquery:
+		colName, err := b.colName(table, c.column)
+		if err != nil {
+			return err
 		}
 		if table.alias != "" {
 			b.quote(table.alias)
 			b.writeByte('.')
 		}
-		b.quote(field.ColName)
+		b.quote(colName)
 	default:
 		return errs.NewErrUnsupportedTable(table)
 	}
@@ -51,13 +103,317 @@ func (b *builder) buildColumn(c *Column) error {
 	return nil
 }
 
+// colName 在 table 中查找字段对应的列名
+func (b *builder) colName(table TableReference, goName string) (string, error) {
+	switch t := table.(type) {
+	case nil:
+		field, err := b.model.FieldByName(goName)
+		if err != nil {
+			return "", err
+		}
+		return field.ColName, nil
+	case Table:
+		m, err := b.r.Get(t.entity)
+		if err != nil {
+			return "", err
+		}
+		field, err := m.FieldByName(goName)
+		if err != nil {
+			return "", err
+		}
+		return field.ColName, nil
+	case Join:
+		colName, err := b.colName(t.left, goName)
+		if err == nil {
+			return colName, nil
+		}
+		return b.colName(t.right, goName)
+	case Subquery:
+		// SELECT * 的时候按照子查询的 FROM 解析
+		if len(t.columns) == 0 {
+			return b.colName(t.from, goName)
+		}
+		for _, c := range t.columns {
+			switch val := c.(type) {
+			case Column:
+				if val.alias == goName {
+					return val.alias, nil
+				}
+				if val.alias == "" && val.column == goName {
+					if val.table != nil {
+						return b.colName(val.table, goName)
+					}
+					return b.colName(t.from, goName)
+				}
+			case Aggregate:
+				if val.alias == goName {
+					return val.alias, nil
+				}
+			case MathExpr:
+				if val.alias == goName {
+					return val.alias, nil
+				}
+			}
+		}
+		return "", errs.NewErrUnknownField(goName)
+	default:
+		return "", errs.NewErrUnsupportedTable(table)
+	}
+}
+
+// buildSubquery 构建 (子查询)，子查询的参数按照出现的顺序合并
+func (b *builder) buildSubquery(sub Subquery) error {
+	q, err := sub.s.buildRaw()
+	if err != nil {
+		return err
+	}
+	b.writeByte('(')
+	b.writeString(q.SQL)
+	b.writeByte(')')
+	b.addArgs(q.Args...)
+	return nil
+}
+
+// buildExpression 表达式编译的统一入口
+// SELECT、UPDATE、DELETE 以及 UPSERT 中出现的表达式都通过这里构建
+// 新增表达式类型时只需要在这里增加对应的 case
+func (b *builder) buildExpression(expression Expression) error {
+	if expression == nil {
+		return nil
+	}
+	switch expr := expression.(type) {
+	case Column:
+		return b.buildColumn(&expr)
+	case Aggregate:
+		return b.buildAggregate(expr, false)
+	case Value:
+		b.writeByte('?')
+		b.addArgs(expr.val)
+	case RawExpr:
+		b.writeString(expr.raw)
+		b.addArgs(expr.args...)
+	case Predicate:
+		return b.buildPredicate(expr)
+	case Subquery:
+		return b.buildSubquery(expr)
+	case MathExpr:
+		if err := b.buildSubExpr(expr.left); err != nil {
+			return err
+		}
+		b.writeByte(' ')
+		b.writeString(string(expr.op))
+		b.writeByte(' ')
+		return b.buildSubExpr(expr.right)
+	case values:
+		b.writeByte('(')
+		for i, val := range expr.vals {
+			if i > 0 {
+				b.writeByte(',')
+			}
+			if err := b.buildExpression(exprOf(val)); err != nil {
+				return err
+			}
+		}
+		b.writeByte(')')
+	case betweenRange:
+		if err := b.buildExpression(expr.start); err != nil {
+			return err
+		}
+		b.writeString(" AND ")
+		return b.buildExpression(expr.end)
+	default:
+		return errs.NewErrUnsupportedExpressionType(expr)
+	}
+	return nil
+}
+
+func (b *builder) buildPredicate(p Predicate) error {
+	if err := b.buildSubExpr(p.left); err != nil {
+		return err
+	}
+
+	// 可能只有左边
+	if p.op == "" {
+		return nil
+	}
+
+	// NOT 这种只有右边的情况，不需要前置空格
+	if p.left != nil {
+		b.writeByte(' ')
+	}
+	b.writeString(string(p.op))
+	// IS NULL 这种只有左边的情况
+	if p.right == nil {
+		return nil
+	}
+	b.writeByte(' ')
+	right := p.right
+	// 和模型的列比较的值需要和写入的时候一样经过字段的 Serializer
+	// LIKE 的模式不是列的值，不需要转换
+	if c, ok := p.left.(Column); ok && p.op != opLIKE {
+		fd, err := b.columnField(c)
+		if err != nil {
+			return err
+		}
+		if right, err = columnValue(fd, right); err != nil {
+			return err
+		}
+	}
+	return b.buildSubExpr(right)
+}
+
+// columnField 查找列对应的字段，子查询中的列没有对应的字段，返回 nil
+func (b *builder) columnField(c Column) (*model.Field, error) {
+	switch table := c.table.(type) {
+	case nil:
+		return b.model.FieldByName(c.column)
+	case Table:
+		m, err := b.r.Get(table.entity)
+		if err != nil {
+			return nil, err
+		}
+		return m.FieldByName(c.column)
+	default:
+		return nil, nil
+	}
+}
+
+// columnValue 将表达式中的值通过 fd 的 Serializer 转换为写入数据库的值
+// 只处理值、IN 的值列表以及 BETWEEN 的范围，其余表达式原样返回
+func columnValue(fd *model.Field, expr Expression) (Expression, error) {
+	if fd == nil || fd.Serializer == nil {
+		return expr, nil
+	}
+	switch e := expr.(type) {
+	case Value:
+		val, err := fd.ColumnValue(e.val)
+		if err != nil {
+			return nil, err
+		}
+		return Value{val: val}, nil
+	case values:
+		vals := make([]any, 0, len(e.vals))
+		for _, val := range e.vals {
+			res, err := columnValue(fd, exprOf(val))
+			if err != nil {
+				return nil, err
+			}
+			vals = append(vals, res)
+		}
+		return values{vals: vals}, nil
+	case betweenRange:
+		start, err := columnValue(fd, e.start)
+		if err != nil {
+			return nil, err
+		}
+		end, err := columnValue(fd, e.end)
+		if err != nil {
+			return nil, err
+		}
+		return betweenRange{start: start, end: end}, nil
+	default:
+		return expr, nil
+	}
+}
+
+// buildSubExpr 构建子表达式，复合的表达式需要用括号包起来
+func (b *builder) buildSubExpr(expression Expression) error {
+	switch expression.(type) {
+	case Predicate, MathExpr:
+		b.writeByte('(')
+		if err := b.buildExpression(expression); err != nil {
+			return err
+		}
+		b.writeByte(')')
+		return nil
+	default:
+		return b.buildExpression(expression)
+	}
+}
+
+// buildPredicates 多个 Predicate 之间使用 AND 连接
+func (b *builder) buildPredicates(ps []Predicate) error {
+	if len(ps) == 0 {
+		return nil
+	}
+	p := ps[0]
+	for i := 1; i < len(ps); i++ {
+		p = p.And(ps[i])
+	}
+	return b.buildExpression(p)
+}
+
+func (b *builder) buildAggregate(a Aggregate, useAlias bool) error {
+	b.writeString(a.fn)
+	b.writeByte('(')
+	if err := b.buildColumn(&Column{column: a.arg}); err != nil {
+		return err
+	}
+	b.writeByte(')')
+	if useAlias {
+		b.buildAs(a.alias)
+	}
+	return nil
+}
+
+// buildSelectable 构建 SELECT 后面的单个元素，和 WHERE 不同，这里会使用别名
+func (b *builder) buildSelectable(s Selectable) error {
+	switch val := s.(type) {
+	case Column:
+		if err := b.buildColumn(&val); err != nil {
+			return err
+		}
+		b.buildAs(val.alias)
+	case Aggregate:
+		return b.buildAggregate(val, true)
+	case MathExpr:
+		if err := b.buildExpression(val); err != nil {
+			return err
+		}
+		b.buildAs(val.alias)
+	case RawExpr:
+		b.writeString(val.raw)
+		b.addArgs(val.args...)
+	default:
+		return errs.NewErrUnsupportedExpressionType(val)
+	}
+	return nil
+}
+
+// buildAssignment 构建 col=val，val 可以是任意表达式
+// val 是普通的值的时候和写入实体一样需要经过字段的 Serializer
+func (b *builder) buildAssignment(a Assignment) error {
+	if err := b.buildColumn(&Column{column: a.column}); err != nil {
+		return err
+	}
+	b.writeByte('=')
+	fd, err := b.model.FieldByName(a.column)
+	if err != nil {
+		return err
+	}
+	expr, err := columnValue(fd, exprOf(a.val))
+	if err != nil {
+		return err
+	}
+	return b.buildExpression(expr)
+}
+
+// buildAs 构建as
+func (b *builder) buildAs(alias string) {
+	if alias != "" {
+		b.writeString(" AS ")
+		b.quote(alias)
+	}
+}
+
 func (b *builder) quote(column string) {
-	b.writeByte(b.quoter)
-	b.writeString(column)
-	b.writeByte(b.quoter)
+	b.writeString(b.dialect.Quote(column))
 }
 
 func (b *builder) addArgs(args ...any) {
+	if len(args) == 0 {
+		return
+	}
 	if b.args == nil {
 		// 很少有查询能够超过八个参数
 		// INSERT 除外
@@ -65,3 +421,39 @@ func (b *builder) addArgs(args ...any) {
 	}
 	b.args = append(b.args, args...)
 }
+
+func (b *builder) WriteString(str string) (int, error) {
+	return b.buffer.WriteString(str)
+}
+
+func (b *builder) WriteByte(c byte) error {
+	return b.buffer.WriteByte(c)
+}
+
+func (b *builder) Quote(name string) {
+	b.quote(name)
+}
+
+func (b *builder) AddArgs(args ...any) {
+	b.addArgs(args...)
+}
+
+func (b *builder) Model() *model.Model {
+	return b.model
+}
+
+func (b *builder) ColName(goName string) (string, error) {
+	return b.colName(nil, goName)
+}
+
+func (b *builder) BuildColumn(goName string) error {
+	return b.buildColumn(&Column{column: goName})
+}
+
+func (b *builder) BuildExpression(e Expression) error {
+	return b.buildExpression(e)
+}
+
+func (b *builder) BuildAssignment(a Assignment) error {
+	return b.buildAssignment(a)
+}
@@ -15,27 +15,68 @@ func (Column) assign() {}
 
 // sub.C("name").Eq(12)
 func (c Column) Eq(arg any) Predicate {
-	return Predicate{
-		left:  c,
-		op:    opEQ,
-		right: exprOf(arg),
-	}
+	return binary(c, opEQ, arg)
+}
+
+func (c Column) NotEq(arg any) Predicate {
+	return binary(c, opNEQ, arg)
 }
 
 func (c Column) LT(arg any) Predicate {
-	return Predicate{
-		left:  c,
-		op:    opLT,
-		right: exprOf(arg),
-	}
+	return binary(c, opLT, arg)
+}
+
+func (c Column) LTEq(arg any) Predicate {
+	return binary(c, opLTEQ, arg)
 }
 
 func (c Column) GT(arg any) Predicate {
-	return Predicate{
-		left:  c,
-		op:    opGT,
-		right: exprOf(arg),
-	}
+	return binary(c, opGT, arg)
+}
+
+func (c Column) GTEq(arg any) Predicate {
+	return binary(c, opGTEQ, arg)
+}
+
+// In C("Id").In(1, 2, 3) 或者 C("Id").In([]int{1, 2, 3})
+func (c Column) In(vals ...any) Predicate {
+	return in(c, opIN, vals)
+}
+
+func (c Column) NotIn(vals ...any) Predicate {
+	return in(c, opNOTIN, vals)
+}
+
+func (c Column) Between(start any, end any) Predicate {
+	return between(c, start, end)
+}
+
+func (c Column) Like(pattern string) Predicate {
+	return binary(c, opLIKE, pattern)
+}
+
+func (c Column) IsNull() Predicate {
+	return unary(c, opISNULL)
+}
+
+func (c Column) IsNotNull() Predicate {
+	return unary(c, opISNOTNULL)
+}
+
+func (c Column) Add(val any) MathExpr {
+	return math(c, opADD, val)
+}
+
+func (c Column) Sub(val any) MathExpr {
+	return math(c, opSUB, val)
+}
+
+func (c Column) Multi(val any) MathExpr {
+	return math(c, opMULTI, val)
+}
+
+func (c Column) Div(val any) MathExpr {
+	return math(c, opDIV, val)
 }
 
 func exprOf(arg any) Expression {
@@ -51,6 +92,11 @@ func C(name string) Column {
 	return Column{column: name}
 }
 
+// Name 返回结构体的字段名
+func (c Column) Name() string {
+	return c.column
+}
+
 func (c Column) As(alias string) Column {
 	return Column{
 		column: c.column,
@@ -3,13 +3,26 @@ package orm
 
 import (
 	"context"
+	"database/sql"
 	"orm_framework/orm/internal/valuer"
 	"orm_framework/orm/model"
 )
 
+// MappingMode 结果集的映射模式
+type MappingMode = valuer.Mapping
+
+const (
+	// MappingStrict 结果集中有模型不认识的列时返回错误，默认使用
+	MappingStrict = valuer.MappingStrict
+	// MappingLenient 丢弃模型不认识的列，适合 SELECT * 的场景
+	MappingLenient = valuer.MappingLenient
+)
+
 type core struct {
 	// 用于返回结果集处理
 	valuer.Creator
+	// mapping 结果集的映射模式
+	mapping MappingMode
 	// dialect 方言
 	dialect Dialect
 	// r 使用隔离的DB维护一个注册中心
@@ -18,6 +31,15 @@ type core struct {
 	mdls  []Middleware
 }
 
+// newValue 创建使用 mapping 映射结果集的 Value
+func (c core) newValue(entity any, meta *model.Model) valuer.Value {
+	val := c.Creator(entity, meta)
+	if c.mapping == MappingStrict {
+		return val
+	}
+	return valuer.WithMapping(val, c.mapping)
+}
+
 func get[T any](ctx context.Context, sess Session, c core, qc *QueryContext) *QueryResult {
 	var root Handler = func(ctx context.Context, qc *QueryContext) *QueryResult {
 		return getHandler[T](ctx, sess, c, qc)
@@ -60,14 +82,121 @@ func getHandler[T any](ctx context.Context, sess Session, c core, qc *QueryConte
 	if err != nil {
 		return &QueryResult{
 			Result: nil,
-			Err:    ErrNoRows,
+			Err:    err,
 		}
 	}
-	val := c.Creator(tp, meta)
+	val := c.newValue(tp, meta)
 	err = val.SetColumns(rows)
+	if err != nil {
+		return &QueryResult{
+			Err: err,
+		}
+	}
 	return &QueryResult{
 		Result: tp,
-		Err:    err,
+	}
+}
+
+func getMulti[T any](ctx context.Context, sess Session, c core, qc *QueryContext) *QueryResult {
+	var root Handler = func(ctx context.Context, qc *QueryContext) *QueryResult {
+		return getMultiHandler[T](ctx, sess, c, qc)
+	}
+	for index := len(c.mdls) - 1; index >= 0; index-- {
+		root = c.mdls[index](root)
+	}
+	return root(ctx, qc)
+}
+
+// getMultiHandler 逐行将结果集映射为 T
+// 没有数据时返回空切片，而不是 ErrNoRows
+func getMultiHandler[T any](ctx context.Context, sess Session, c core, qc *QueryContext) *QueryResult {
+	sql, err := qc.Builder.Build()
+	if err != nil {
+		return &QueryResult{
+			Err: err,
+		}
+	}
+	rows, err := sess.queryContext(ctx, sql.SQL, sql.Args...)
+	if rows != nil {
+		defer rows.Close()
+	}
+	if err != nil {
+		return &QueryResult{
+			Err: err,
+		}
+	}
+
+	meta, err := c.r.Get(new(T))
+	if err != nil {
+		return &QueryResult{
+			Err: err,
+		}
+	}
+	res := make([]*T, 0, 8)
+	for rows.Next() {
+		tp := new(T)
+		val := c.newValue(tp, meta)
+		if err = val.SetColumns(rows); err != nil {
+			return &QueryResult{
+				Err: err,
+			}
+		}
+		res = append(res, tp)
+	}
+	// 遍历过程中可能出现网络等错误
+	if err = rows.Err(); err != nil {
+		return &QueryResult{
+			Err: err,
+		}
+	}
+	return &QueryResult{
+		Result: res,
+	}
+}
+
+// query 执行查询，并使用 scan 处理结果集
+// 和 get 一样会经过全部的 middleware
+func query(ctx context.Context, sess Session, c core, qc *QueryContext,
+	scan func(rows *sql.Rows) (any, error)) *QueryResult {
+	var root Handler = func(ctx context.Context, qc *QueryContext) *QueryResult {
+		return queryHandler(ctx, sess, qc, scan)
+	}
+	for index := len(c.mdls) - 1; index >= 0; index-- {
+		root = c.mdls[index](root)
+	}
+	return root(ctx, qc)
+}
+
+func queryHandler(ctx context.Context, sess Session, qc *QueryContext,
+	scan func(rows *sql.Rows) (any, error)) *QueryResult {
+	q, err := qc.Builder.Build()
+	if err != nil {
+		return &QueryResult{
+			Err: err,
+		}
+	}
+	rows, err := sess.queryContext(ctx, q.SQL, q.Args...)
+	if rows != nil {
+		defer rows.Close()
+	}
+	if err != nil {
+		return &QueryResult{
+			Err: err,
+		}
+	}
+	res, err := scan(rows)
+	if err != nil {
+		return &QueryResult{
+			Err: err,
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return &QueryResult{
+			Err: err,
+		}
+	}
+	return &QueryResult{
+		Result: res,
 	}
 }
 
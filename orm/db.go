@@ -20,6 +20,8 @@ type DB struct {
 
 	// db 使用到了装饰器模式
 	db *sql.DB
+	// ns 通过 WithNamingStrategy 指定的命名策略，在所有选项生效之后创建 Registry
+	ns model.NamingStrategy
 }
 
 type DBOptions func(db *DB)
@@ -37,8 +39,6 @@ func Open(driver string, dsn string, opts ...DBOptions) (*DB, error) {
 func OpenDB(db *sql.DB, opts ...DBOptions) (*DB, error) {
 	res := &DB{
 		core: core{
-			r:       model.NewRegistry(),
-			Creator: valuer.NewUnsafeValue,
 			dialect: MySQLDialect,
 		},
 		db: db,
@@ -46,9 +46,30 @@ func OpenDB(db *sql.DB, opts ...DBOptions) (*DB, error) {
 	for _, opt := range opts {
 		opt(res)
 	}
+	if res.ns != nil {
+		// 命名策略属于 Registry，同时指定的时候无法确定使用哪一个
+		if res.r != nil {
+			return nil, errs.ErrNamingStrategyWithRegistry
+		}
+		res.r = model.NewRegistry(model.WithNamingStrategy(res.ns))
+	}
+	if res.r == nil {
+		res.r = model.NewRegistry()
+	}
+	// 没有指定 Value 的实现时，优先使用 orm_gen 生成的 Value
+	if res.Creator == nil {
+		res.Creator = valuer.WithGenerated(valuer.NewUnsafeValue)
+	}
 	return res, nil
 }
 
+// WithStandardDialect 使用 ANSI SQL
+func WithStandardDialect() DBOptions {
+	return func(db *DB) {
+		db.dialect = StandardDialect
+	}
+}
+
 func WithMySQLDialect() DBOptions {
 	return func(db *DB) {
 		db.dialect = MySQLDialect
@@ -61,24 +82,66 @@ func WithSqlite3Dialect() DBOptions {
 	}
 }
 
+// WithSqlite3LegacyDialect 3.35 之前的 SQLite 使用
+func WithSqlite3LegacyDialect() DBOptions {
+	return func(db *DB) {
+		db.dialect = SQLLiteLegacyDialect
+	}
+}
+
+func WithPostgresDialect() DBOptions {
+	return func(db *DB) {
+		db.dialect = PostgreSQLDialect
+	}
+}
+
+func WithSQLServerDialect() DBOptions {
+	return func(db *DB) {
+		db.dialect = SQLServerDialect
+	}
+}
+
 func WithDialect(dialect Dialect) DBOptions {
 	return func(db *DB) {
 		db.dialect = dialect
 	}
 }
 
+// WithNamingStrategy 使用指定命名策略的 Registry，不能和 WithRegistry 一起使用
+// 自定义的 Registry 通过 model.WithNamingStrategy 指定命名策略
+func WithNamingStrategy(ns model.NamingStrategy) DBOptions {
+	return func(db *DB) {
+		db.ns = ns
+	}
+}
+
 func WithRegistry(r model.Registry) DBOptions {
 	return func(db *DB) {
 		db.r = r
 	}
 }
 
+// WithReflectValue 使用反射实现的 Value，生成的 Value 也不会生效
 func WithReflectValue() DBOptions {
 	return func(db *DB) {
 		db.Creator = valuer.NewReflectValue
 	}
 }
 
+// WithUnsafeValue 使用 unsafe 实现的 Value，生成的 Value 也不会生效
+func WithUnsafeValue() DBOptions {
+	return func(db *DB) {
+		db.Creator = valuer.NewUnsafeValue
+	}
+}
+
+// WithMappingMode 设置结果集的映射模式，单个查询可以通过 Mapping 覆盖
+func WithMappingMode(mode MappingMode) DBOptions {
+	return func(db *DB) {
+		db.mapping = mode
+	}
+}
+
 func WithMiddleWare(mdls ...Middleware) DBOptions {
 	return func(db *DB) {
 		db.mdls = mdls
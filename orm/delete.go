@@ -0,0 +1,111 @@
+// create by chencanhua in 2023/9/17
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"github.com/valyala/bytebufferpool"
+	"orm_framework/orm/internal/errs"
+)
+
+var (
+	_ QueryBuilder = &Deleter[any]{}
+	_ Executor     = &Deleter[any]{}
+)
+
+// Deleter 用于构建 DELETE 语句
+type Deleter[T any] struct {
+	table TableReference
+	deleteBuilder
+	sess Session
+}
+
+func NewDeleter[T any](sess Session) *Deleter[T] {
+	c := sess.getCore()
+	return &Deleter[T]{
+		deleteBuilder: deleteBuilder{
+			builder: builder{
+				core: c,
+			},
+		},
+		sess: sess,
+	}
+}
+
+// From 指定删除的表，只支持 Table
+// 指定之后 Where 中的列也会按照该表进行解析
+func (d *Deleter[T]) From(table TableReference) *Deleter[T] {
+	d.table = table
+	return d
+}
+
+func (d *Deleter[T]) Where(ps ...Predicate) *Deleter[T] {
+	d.where = ps
+	return d
+}
+
+// Limit 限制删除的行数，需要方言支持
+func (d *Deleter[T]) Limit(limit int) *Deleter[T] {
+	d.limit = limit
+	return d
+}
+
+func (d *Deleter[T]) Build() (*Query, error) {
+	d.reset()
+	defer bytebufferpool.Put(d.buffer)
+	d.writeString("DELETE FROM ")
+	switch t := d.table.(type) {
+	case nil:
+		m, err := d.r.Get(new(T))
+		if err != nil {
+			return nil, err
+		}
+		d.model = m
+		d.quote(m.TableName)
+	case Table:
+		m, err := d.r.Get(t.entity)
+		if err != nil {
+			return nil, err
+		}
+		d.model = m
+		d.quote(m.TableName)
+		if t.alias != "" {
+			d.writeString(" AS ")
+			d.quote(t.alias)
+		}
+	default:
+		return nil, errs.NewErrUnsupportedTable(t)
+	}
+
+	if len(d.where) > 0 {
+		d.writeString(" WHERE ")
+		if err := d.buildPredicates(d.where); err != nil {
+			return nil, err
+		}
+	}
+
+	if d.limit > 0 {
+		if err := d.dialect.BuildDeleteLimit(&d.builder, d.limit); err != nil {
+			return nil, err
+		}
+	}
+
+	return d.end(), nil
+}
+
+func (d *Deleter[T]) Exec(ctx context.Context) sql.Result {
+	qc := &QueryContext{
+		Type:    "DELETE",
+		Builder: d,
+	}
+	result := exec(ctx, d.sess, d.core, qc)
+	if result.Result != nil {
+		return &Result{
+			res: result.Result.(sql.Result),
+			err: nil,
+		}
+	}
+	return &Result{
+		err: result.Err,
+	}
+}
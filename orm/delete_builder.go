@@ -0,0 +1,12 @@
+// create by chencanhua in 2023/9/17
+package orm
+
+type deleterBuilderAttribute struct {
+	where []Predicate
+	limit int
+}
+
+type deleteBuilder struct {
+	builder
+	deleterBuilderAttribute
+}
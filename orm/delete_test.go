@@ -0,0 +1,160 @@
+// create by chencanhua in 2023/9/17
+package orm
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"orm_framework/orm/internal/errs"
+	"testing"
+)
+
+func TestDeleter_Build(t *testing.T) {
+	d := mysqlDB()
+	db, _ := OpenDB(d)
+	sqliteDB, _ := OpenDB(d, WithSqlite3Dialect())
+	type OrderDetail struct {
+		OrderId int
+		ItemId  int
+	}
+	join := TableOf(&TestModel{}).Join(TableOf(&OrderDetail{})).Using("Id")
+	testCases := []struct {
+		name      string
+		d         QueryBuilder
+		wantQuery *Query
+		wantErr   error
+	}{
+		{
+			name: "no where",
+			d:    NewDeleter[TestModel](db),
+			wantQuery: &Query{
+				SQL: "DELETE FROM `test_model`;",
+			},
+		},
+		{
+			name: "where",
+			d: NewDeleter[TestModel](db).
+				Where(C("Id").Eq(1).Or(C("Age").LT(18))),
+			wantQuery: &Query{
+				SQL:  "DELETE FROM `test_model` WHERE (`id` = ?) OR (`age` < ?);",
+				Args: []any{1, 18},
+			},
+		},
+		{
+			name: "multiple where",
+			d: NewDeleter[TestModel](db).
+				Where(C("Id").Eq(1), C("Age").GT(18)),
+			wantQuery: &Query{
+				SQL:  "DELETE FROM `test_model` WHERE (`id` = ?) AND (`age` > ?);",
+				Args: []any{1, 18},
+			},
+		},
+		{
+			name: "invalid column",
+			d: NewDeleter[TestModel](db).
+				Where(C("Invalid").Eq(1)),
+			wantErr: errs.NewErrUnknownField("Invalid"),
+		},
+		{
+			name: "from",
+			d: NewDeleter[TestModel](db).From(TableOf(&OrderDetail{})).
+				Where(C("OrderId").Eq(1)),
+			wantQuery: &Query{
+				SQL:  "DELETE FROM `order_detail` WHERE `order_id` = ?;",
+				Args: []any{1},
+			},
+		},
+		{
+			name:    "unsupported table",
+			d:       NewDeleter[TestModel](db).From(join),
+			wantErr: errs.NewErrUnsupportedTable(join),
+		},
+		{
+			name: "limit",
+			d: NewDeleter[TestModel](db).
+				Where(C("Age").GT(18)).Limit(10),
+			wantQuery: &Query{
+				SQL:  "DELETE FROM `test_model` WHERE `age` > ? LIMIT ?;",
+				Args: []any{18, 10},
+			},
+		},
+		{
+			// SQLite 默认不支持 DELETE ... LIMIT
+			name: "sqlite limit",
+			d: NewDeleter[TestModel](sqliteDB).
+				Where(C("Age").GT(18)).Limit(10),
+			wantErr: errs.NewErrUnsupportedDeleteLimit(10),
+		},
+		{
+			name: "sqlite",
+			d: NewDeleter[TestModel](sqliteDB).
+				Where(C("Age").GT(18)),
+			wantQuery: &Query{
+				SQL:  "DELETE FROM `test_model` WHERE `age` > ?;",
+				Args: []any{18},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := tc.d.Build()
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantQuery, query)
+		})
+	}
+}
+
+func TestDeleter_Exec(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	db, err := OpenDB(mockDB)
+	require.NoError(t, err)
+	testCases := []struct {
+		name     string
+		d        *Deleter[TestModel]
+		wantErr  error
+		affected int64
+	}{
+		{
+			name:    "query error",
+			d:       NewDeleter[TestModel](db).Where(C("Invalid").Eq(1)),
+			wantErr: errs.NewErrUnknownField("Invalid"),
+		},
+		{
+			name: "db error",
+			d: func() *Deleter[TestModel] {
+				mock.ExpectExec("DELETE .*").
+					WillReturnError(errors.New("db error"))
+				return NewDeleter[TestModel](db).Where(C("Id").Eq(1))
+			}(),
+			wantErr: errors.New("db error"),
+		},
+		{
+			name: "exec",
+			d: func() *Deleter[TestModel] {
+				res := driver.RowsAffected(1)
+				mock.ExpectExec("DELETE .*").WillReturnResult(res)
+				return NewDeleter[TestModel](db).Where(C("Id").Eq(1))
+			}(),
+			affected: 1,
+		},
+	}
+	for _, ts := range testCases {
+		t.Run(ts.name, func(t *testing.T) {
+			res := ts.d.Exec(context.Background())
+			affected, err := res.RowsAffected()
+			assert.Equal(t, ts.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, ts.affected, affected)
+		})
+	}
+}
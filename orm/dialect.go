@@ -1,115 +1,646 @@
 // create by chencanhua in 2023/6/23
 package orm
 
-import "orm_framework/orm/internal/errs"
+import (
+	"database/sql"
+	"orm_framework/orm/internal/errs"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
 
+// Dialect 方言
+// 第三方可以实现该接口来支持其它数据库，通过 WithDialect 使用
+// 可以嵌入已有的方言，只覆盖不一样的部分
 type Dialect interface {
-	quoter() byte
-	buildOnUpsert(b *builder, odk *Upsert) error
+	// Name 方言名称，用于错误信息
+	Name() string
+	// Quote 引用标识符，例如 MySQL 中 name => `name`
+	Quote(name string) string
+	// BindVars 改写占位符，构建过程中统一使用 ?
+	BindVars(query string) string
+	// BuildLimitOffset 构建分页，limit 和 offset 为 0 代表没有设置
+	BuildLimitOffset(b SelectBuilder, limit int, offset int) error
+	// BuildDeleteLimit 构建 DELETE 语句的 LIMIT 部分，并不是所有数据库都支持
+	BuildDeleteLimit(b Builder, limit int) error
+	// BuildUpsert 构建 UPSERT 的冲突处理部分
+	BuildUpsert(b Builder, upsert *Upsert) error
+	// SupportReturning 是否支持 RETURNING
+	SupportReturning() bool
+	// BuildReturning 构建 RETURNING 部分，columns 为列名
+	BuildReturning(b Builder, columns []string) error
+	// ColumnType 返回 Go 类型对应的数据库类型
+	ColumnType(typ reflect.Type) (string, error)
+}
+
+// OutputDialect 使用 OUTPUT 返回插入数据的方言，例如 SQL Server
+// OUTPUT 位于 VALUES 之前，Inserter 会调用 BuildOutput 而不是 BuildReturning
+type OutputDialect interface {
+	Dialect
+	BuildOutput(b Builder, columns []string) error
+}
+
+// lastRowIdDialect LastInsertId 是批量插入的最后一行而不是第一行的 id 的方言
+type lastRowIdDialect interface {
+	lastRowId() bool
+}
+
+// MergeDialect 使用 MERGE 语句实现 UPSERT 的方言
+// 设置了 UPSERT 时 Inserter 会将整个语句交给 BuildMerge 构建，不再调用 BuildUpsert
+type MergeDialect interface {
+	Dialect
+	BuildMerge(b Builder, m *Merge) error
 }
 
 var (
-	MySQLDialect   Dialect = &mysqlDialect{}
-	SQLLiteDialect Dialect = &sqlite3Dialect{}
+	StandardDialect Dialect = &standardSQL{}
+	MySQLDialect    Dialect = &mysqlDialect{}
+	SQLLiteDialect  Dialect = &sqlite3Dialect{}
+	// SQLLiteLegacyDialect 3.35 之前的 SQLite，不支持 RETURNING，自增列通过 LastInsertId 回填
+	SQLLiteLegacyDialect Dialect = &sqlite3Dialect{legacy: true}
+	PostgreSQLDialect    Dialect = &postgresDialect{}
+	SQLServerDialect     Dialect = &sqlServerDialect{}
 )
 
+// baseDialect 各个方言共有的默认实现
+type baseDialect struct {
+}
+
+func (s *baseDialect) BindVars(query string) string {
+	return query
+}
+
+func (s *baseDialect) BuildLimitOffset(b SelectBuilder, limit int, offset int) error {
+	if limit > 0 {
+		_, _ = b.WriteString(" LIMIT ?")
+		b.AddArgs(limit)
+	}
+	if offset > 0 {
+		_, _ = b.WriteString(" OFFSET ?")
+		b.AddArgs(offset)
+	}
+	return nil
+}
+
+func (s *baseDialect) BuildDeleteLimit(b Builder, limit int) error {
+	return errs.NewErrUnsupportedDeleteLimit(limit)
+}
+
+func (s *baseDialect) SupportReturning() bool {
+	return false
+}
+
+func (s *baseDialect) BuildReturning(b Builder, columns []string) error {
+	return errs.ErrUnsupportedReturning
+}
+
+func (s *baseDialect) ColumnType(typ reflect.Type) (string, error) {
+	return columnType(typ, map[string]string{
+		typeBool:    "BOOLEAN",
+		typeInt8:    "SMALLINT",
+		typeInt16:   "SMALLINT",
+		typeInt32:   "INTEGER",
+		typeInt64:   "BIGINT",
+		typeFloat32: "REAL",
+		typeFloat64: "DOUBLE PRECISION",
+		typeString:  "VARCHAR(255)",
+		typeBytes:   "BLOB",
+		typeTime:    "TIMESTAMP",
+	})
+}
+
+// standardSQL ANSI SQL
+// 标准 SQL 中 DELETE 没有 LIMIT，也没有 RETURNING
 type standardSQL struct {
+	baseDialect
 }
 
-func (s *standardSQL) quoter() byte {
-	//TODO implement me
-	panic("implement me")
+func (s *standardSQL) Name() string {
+	return "standard"
 }
 
-func (s *standardSQL) buildOnUpsert() error {
-	//TODO implement me
-	panic("implement me")
+func (s *standardSQL) Quote(name string) string {
+	return `"` + name + `"`
+}
+
+// BuildLimitOffset OFFSET n ROWS FETCH FIRST m ROWS ONLY
+func (s *standardSQL) BuildLimitOffset(b SelectBuilder, limit int, offset int) error {
+	if offset > 0 {
+		_, _ = b.WriteString(" OFFSET ? ROWS")
+		b.AddArgs(offset)
+	}
+	if limit > 0 {
+		_, _ = b.WriteString(" FETCH FIRST ? ROWS ONLY")
+		b.AddArgs(limit)
+	}
+	return nil
+}
+
+// BuildUpsert 标准 SQL 使用 MERGE 实现 UPSERT，见 BuildMerge
+func (s *standardSQL) BuildUpsert(b Builder, upsert *Upsert) error {
+	return errs.NewErrUnsupportedUpsert(s.Name())
+}
+
+func (s *standardSQL) BuildMerge(b Builder, m *Merge) error {
+	return buildMerge(b, m)
 }
 
 type mysqlDialect struct {
-	standardSQL
+	baseDialect
 }
 
-func (s *mysqlDialect) quoter() byte {
-	return '`'
+func (s *mysqlDialect) Name() string {
+	return "mysql"
 }
 
-func (s *mysqlDialect) buildOnUpsert(b *builder, odk *Upsert) error {
-	b.writeString(" ON DUPLICATE KEY UPDATE ")
-	var err error
-	for index, a := range odk.assigns {
+func (s *mysqlDialect) Quote(name string) string {
+	return "`" + name + "`"
+}
+
+func (s *mysqlDialect) BuildUpsert(b Builder, odk *Upsert) error {
+	_, _ = b.WriteString(" ON DUPLICATE KEY UPDATE ")
+	// MySQL 没有 DO NOTHING，使用 col=col 达到同样的效果
+	if odk.DoNothing() {
+		fd := b.Model().Fields[0]
+		b.Quote(fd.ColName)
+		_ = b.WriteByte('=')
+		b.Quote(fd.ColName)
+		return nil
+	}
+	for index, a := range odk.Assigns() {
 		if index > 0 {
-			b.writeByte(',')
+			_ = b.WriteByte(',')
 		}
 		switch assign := a.(type) {
 		case Assignment:
-			err = b.buildColumn(&Column{column: assign.column})
-			if err != nil {
+			if err := b.BuildAssignment(assign); err != nil {
 				return err
 			}
-			b.writeString(`=?`)
-			b.addArgs(assign.val)
 		case Column:
-			err = b.buildColumn(&Column{column: assign.column})
+			colName, err := b.ColName(assign.Name())
 			if err != nil {
 				return err
 			}
-			b.writeString("=VALUES(")
-			b.buildColumn(&Column{column: assign.column})
-			b.writeString(")")
+			b.Quote(colName)
+			_, _ = b.WriteString("=VALUES(")
+			b.Quote(colName)
+			_ = b.WriteByte(')')
+		default:
+			return errs.NewErrUnsupportedAssignableType(a)
 		}
 	}
 	return nil
 }
 
+func (s *mysqlDialect) BuildDeleteLimit(b Builder, limit int) error {
+	_, _ = b.WriteString(" LIMIT ?")
+	b.AddArgs(limit)
+	return nil
+}
+
+func (s *mysqlDialect) ColumnType(typ reflect.Type) (string, error) {
+	return columnType(typ, map[string]string{
+		typeBool:    "TINYINT(1)",
+		typeInt8:    "TINYINT",
+		typeInt16:   "SMALLINT",
+		typeInt32:   "INT",
+		typeInt64:   "BIGINT",
+		typeFloat32: "FLOAT",
+		typeFloat64: "DOUBLE",
+		typeString:  "VARCHAR(255)",
+		typeBytes:   "BLOB",
+		typeTime:    "DATETIME",
+	})
+}
+
+// sqlite3Dialect 默认要求 SQLite 3.35 及以上的版本
 type sqlite3Dialect struct {
-	standardSQL
+	baseDialect
+	// legacy 3.35 之前的版本
+	legacy bool
+}
+
+func (s *sqlite3Dialect) Name() string {
+	return "sqlite3"
+}
+
+func (s *sqlite3Dialect) Quote(name string) string {
+	return "`" + name + "`"
+}
+
+func (s *sqlite3Dialect) BuildUpsert(b Builder, odk *Upsert) error {
+	return buildOnConflict(b, odk)
+}
+
+// SupportReturning SQLite 3.35 开始支持 RETURNING，之前的版本使用 SQLLiteLegacyDialect
+func (s *sqlite3Dialect) SupportReturning() bool {
+	return !s.legacy
+}
+
+// lastRowId SQLite 的 LastInsertId 是批量插入的最后一行的 id
+func (s *sqlite3Dialect) lastRowId() bool {
+	return true
+}
+
+func (s *sqlite3Dialect) BuildReturning(b Builder, columns []string) error {
+	return buildReturning(b, columns)
+}
+
+func (s *sqlite3Dialect) ColumnType(typ reflect.Type) (string, error) {
+	return columnType(typ, map[string]string{
+		typeBool:    "BOOLEAN",
+		typeInt8:    "INTEGER",
+		typeInt16:   "INTEGER",
+		typeInt32:   "INTEGER",
+		typeInt64:   "INTEGER",
+		typeFloat32: "REAL",
+		typeFloat64: "REAL",
+		typeString:  "TEXT",
+		typeBytes:   "BLOB",
+		typeTime:    "DATETIME",
+	})
+}
+
+type postgresDialect struct {
+	baseDialect
+}
+
+func (s *postgresDialect) Name() string {
+	return "postgres"
+}
+
+func (s *postgresDialect) Quote(name string) string {
+	return `"` + name + `"`
+}
+
+// BindVars 将 ? 改写为 $1...$N
+// 字符串以及引号中的 ? 保持不变
+func (s *postgresDialect) BindVars(query string) string {
+	return bindVars(query, "$")
+}
+
+func (s *postgresDialect) BuildUpsert(b Builder, odk *Upsert) error {
+	// PostgreSQL 的 DO UPDATE 必须指定冲突的列
+	if !odk.DoNothing() && len(odk.ConflictColumns()) == 0 {
+		return errs.ErrUpsertConflictColumnsRequired
+	}
+	return buildOnConflict(b, odk)
+}
+
+func (s *postgresDialect) SupportReturning() bool {
+	return true
+}
+
+func (s *postgresDialect) BuildReturning(b Builder, columns []string) error {
+	return buildReturning(b, columns)
+}
+
+func (s *postgresDialect) ColumnType(typ reflect.Type) (string, error) {
+	return columnType(typ, map[string]string{
+		typeBool:    "BOOLEAN",
+		typeInt8:    "SMALLINT",
+		typeInt16:   "SMALLINT",
+		typeInt32:   "INTEGER",
+		typeInt64:   "BIGINT",
+		typeFloat32: "REAL",
+		typeFloat64: "DOUBLE PRECISION",
+		typeString:  "TEXT",
+		typeBytes:   "BYTEA",
+		typeTime:    "TIMESTAMP",
+	})
 }
 
-func (s *sqlite3Dialect) quoter() byte {
-	return '`'
+type sqlServerDialect struct {
+	baseDialect
 }
 
-func (s *sqlite3Dialect) buildOnUpsert(b *builder, odk *Upsert) error {
-	b.writeString(" ON CONFLICT")
-	if len(odk.conflictColumns) > 0 {
-		b.writeByte('(')
-		for i, col := range odk.conflictColumns {
+func (s *sqlServerDialect) Name() string {
+	return "sqlserver"
+}
+
+func (s *sqlServerDialect) Quote(name string) string {
+	return "[" + name + "]"
+}
+
+// BindVars 将 ? 改写为 @p1...@pN
+func (s *sqlServerDialect) BindVars(query string) string {
+	return bindVars(query, "@p")
+}
+
+// BuildLimitOffset OFFSET n ROWS FETCH NEXT m ROWS ONLY
+// SQL Server 的 OFFSET 必须跟在 ORDER BY 后面，没有排序的时候使用 ORDER BY (SELECT NULL)
+func (s *sqlServerDialect) BuildLimitOffset(b SelectBuilder, limit int, offset int) error {
+	if !b.HasOrderBy() {
+		_, _ = b.WriteString(" ORDER BY (SELECT NULL)")
+	}
+	_, _ = b.WriteString(" OFFSET ? ROWS")
+	b.AddArgs(offset)
+	if limit > 0 {
+		_, _ = b.WriteString(" FETCH NEXT ? ROWS ONLY")
+		b.AddArgs(limit)
+	}
+	return nil
+}
+
+// BuildUpsert SQL Server 使用 MERGE 实现 UPSERT，见 BuildMerge
+func (s *sqlServerDialect) BuildUpsert(b Builder, upsert *Upsert) error {
+	return errs.NewErrUnsupportedUpsert(s.Name())
+}
+
+func (s *sqlServerDialect) BuildMerge(b Builder, m *Merge) error {
+	return buildMerge(b, m)
+}
+
+// SupportReturning SQL Server 通过 OUTPUT 返回插入的数据
+func (s *sqlServerDialect) SupportReturning() bool {
+	return true
+}
+
+// BuildOutput 构建 OUTPUT INSERTED.[col]
+func (s *sqlServerDialect) BuildOutput(b Builder, columns []string) error {
+	_, _ = b.WriteString(" OUTPUT ")
+	for i, col := range columns {
+		if i > 0 {
+			_ = b.WriteByte(',')
+		}
+		_, _ = b.WriteString("INSERTED.")
+		b.Quote(col)
+	}
+	return nil
+}
+
+func (s *sqlServerDialect) ColumnType(typ reflect.Type) (string, error) {
+	return columnType(typ, map[string]string{
+		typeBool:    "BIT",
+		typeInt8:    "SMALLINT",
+		typeInt16:   "SMALLINT",
+		typeInt32:   "INT",
+		typeInt64:   "BIGINT",
+		typeFloat32: "REAL",
+		typeFloat64: "FLOAT",
+		typeString:  "NVARCHAR(255)",
+		typeBytes:   "VARBINARY(MAX)",
+		typeTime:    "DATETIME2",
+	})
+}
+
+// bindVars 将 ? 改写为 prefix 加上序号，例如 $1
+// 字符串以及引号中的 ? 保持不变
+func bindVars(query string, prefix string) string {
+	if !strings.Contains(query, "?") {
+		return query
+	}
+	var sb strings.Builder
+	sb.Grow(len(query) + 8)
+	var quote byte
+	n := 0
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '?':
+			n++
+			sb.WriteString(prefix)
+			sb.WriteString(strconv.Itoa(n))
+			continue
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+// buildOnConflict 构建 ON CONFLICT 形式的 UPSERT，SQLite 和 PostgreSQL 通用
+func buildOnConflict(b Builder, odk *Upsert) error {
+	_, _ = b.WriteString(" ON CONFLICT")
+	if conflictColumns := odk.ConflictColumns(); len(conflictColumns) > 0 {
+		_ = b.WriteByte('(')
+		for i, col := range conflictColumns {
 			if i > 0 {
-				b.writeByte(',')
+				_ = b.WriteByte(',')
 			}
-			err := b.buildColumn(&Column{column: col})
-			if err != nil {
+			if err := b.BuildColumn(col); err != nil {
 				return err
 			}
 		}
-		b.writeByte(')')
+		_ = b.WriteByte(')')
+	}
+	if odk.DoNothing() {
+		_, _ = b.WriteString(" DO NOTHING")
+		return nil
 	}
-	b.writeString(" DO UPDATE SET ")
+	_, _ = b.WriteString(" DO UPDATE SET ")
 
-	for idx, a := range odk.assigns {
+	for idx, a := range odk.Assigns() {
 		if idx > 0 {
-			b.writeByte(',')
+			_ = b.WriteByte(',')
 		}
 		switch assign := a.(type) {
 		case Column:
-			fd, ok := b.model.FieldMap[assign.column]
-			if !ok {
-				return errs.NewErrUnknownField(assign.column)
+			colName, err := b.ColName(assign.Name())
+			if err != nil {
+				return err
 			}
-			b.quote(fd.ColName)
-			b.writeString("=excluded.")
-			b.quote(fd.ColName)
+			b.Quote(colName)
+			_, _ = b.WriteString("=excluded.")
+			b.Quote(colName)
 		case Assignment:
-			err := b.buildColumn(&Column{column: assign.column})
-			if err != nil {
+			if err := b.BuildAssignment(assign); err != nil {
 				return err
 			}
-			b.writeString("=?")
-			b.addArgs(assign.val)
 		default:
 			return errs.NewErrUnsupportedAssignableType(a)
 		}
 	}
 	return nil
 }
+
+// buildMerge 构建 MERGE 形式的 UPSERT
+// MERGE INTO t USING (VALUES (?,?)) AS src(a,b) ON (t.a=src.a)
+// WHEN MATCHED THEN UPDATE SET b=src.b WHEN NOT MATCHED THEN INSERT (a,b) VALUES (src.a,src.b)
+func buildMerge(b Builder, m *Merge) error {
+	upsert := m.Upsert()
+	// MERGE 需要冲突的列来构造 ON 条件
+	if len(upsert.ConflictColumns()) == 0 {
+		return errs.ErrUpsertConflictColumnsRequired
+	}
+	const src = "src"
+	_, _ = b.WriteString("MERGE INTO ")
+	b.Quote(m.Table())
+	_, _ = b.WriteString(" USING (VALUES ")
+	buildValues(b, m.Values())
+	_, _ = b.WriteString(") AS ")
+	b.Quote(src)
+	_ = b.WriteByte('(')
+	for i, col := range m.Columns() {
+		if i > 0 {
+			_ = b.WriteByte(',')
+		}
+		b.Quote(col)
+	}
+	_, _ = b.WriteString(") ON (")
+	for i, goName := range upsert.ConflictColumns() {
+		colName, err := b.ColName(goName)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			_, _ = b.WriteString(" AND ")
+		}
+		b.Quote(m.Table())
+		_ = b.WriteByte('.')
+		b.Quote(colName)
+		_ = b.WriteByte('=')
+		b.Quote(src)
+		_ = b.WriteByte('.')
+		b.Quote(colName)
+	}
+	_ = b.WriteByte(')')
+	if !upsert.DoNothing() {
+		_, _ = b.WriteString(" WHEN MATCHED THEN UPDATE SET ")
+		for idx, a := range upsert.Assigns() {
+			if idx > 0 {
+				_ = b.WriteByte(',')
+			}
+			switch assign := a.(type) {
+			case Column:
+				colName, err := b.ColName(assign.Name())
+				if err != nil {
+					return err
+				}
+				b.Quote(colName)
+				_ = b.WriteByte('=')
+				b.Quote(src)
+				_ = b.WriteByte('.')
+				b.Quote(colName)
+			case Assignment:
+				if err := b.BuildAssignment(assign); err != nil {
+					return err
+				}
+			default:
+				return errs.NewErrUnsupportedAssignableType(a)
+			}
+		}
+	}
+	_, _ = b.WriteString(" WHEN NOT MATCHED THEN INSERT (")
+	for i, col := range m.Columns() {
+		if i > 0 {
+			_ = b.WriteByte(',')
+		}
+		b.Quote(col)
+	}
+	_, _ = b.WriteString(") VALUES (")
+	for i, col := range m.Columns() {
+		if i > 0 {
+			_ = b.WriteByte(',')
+		}
+		b.Quote(src)
+		_ = b.WriteByte('.')
+		b.Quote(col)
+	}
+	_ = b.WriteByte(')')
+	return nil
+}
+
+// buildValues 构建 (?,?),(?,?)
+func buildValues(b Builder, rows [][]any) {
+	for i, row := range rows {
+		if i > 0 {
+			_ = b.WriteByte(',')
+		}
+		_ = b.WriteByte('(')
+		for j := range row {
+			if j > 0 {
+				_ = b.WriteByte(',')
+			}
+			_ = b.WriteByte('?')
+		}
+		_ = b.WriteByte(')')
+		b.AddArgs(row...)
+	}
+}
+
+func buildReturning(b Builder, columns []string) error {
+	_, _ = b.WriteString(" RETURNING ")
+	for i, col := range columns {
+		if i > 0 {
+			_ = b.WriteByte(',')
+		}
+		b.Quote(col)
+	}
+	return nil
+}
+
+// Go 类型的分类，用于不同方言之间的类型映射
+const (
+	typeBool    = "bool"
+	typeInt8    = "int8"
+	typeInt16   = "int16"
+	typeInt32   = "int32"
+	typeInt64   = "int64"
+	typeFloat32 = "float32"
+	typeFloat64 = "float64"
+	typeString  = "string"
+	typeBytes   = "bytes"
+	typeTime    = "time"
+)
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	bytesType     = reflect.TypeOf([]byte{})
+	nullTypeKinds = map[reflect.Type]string{
+		reflect.TypeOf(sql.NullBool{}):    typeBool,
+		reflect.TypeOf(sql.NullByte{}):    typeInt8,
+		reflect.TypeOf(sql.NullInt16{}):   typeInt16,
+		reflect.TypeOf(sql.NullInt32{}):   typeInt32,
+		reflect.TypeOf(sql.NullInt64{}):   typeInt64,
+		reflect.TypeOf(sql.NullFloat64{}): typeFloat64,
+		reflect.TypeOf(sql.NullString{}):  typeString,
+		reflect.TypeOf(sql.NullTime{}):    typeTime,
+	}
+)
+
+// columnType 按照 Go 类型的分类在 types 中查找数据库类型
+func columnType(typ reflect.Type, types map[string]string) (string, error) {
+	origin := typ
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	var kind string
+	if k, ok := nullTypeKinds[typ]; ok {
+		kind = k
+	} else if typ == timeType {
+		kind = typeTime
+	} else if typ == bytesType {
+		kind = typeBytes
+	} else {
+		switch typ.Kind() {
+		case reflect.Bool:
+			kind = typeBool
+		case reflect.Int8, reflect.Uint8:
+			kind = typeInt8
+		case reflect.Int16, reflect.Uint16:
+			kind = typeInt16
+		case reflect.Int32, reflect.Uint32:
+			kind = typeInt32
+		case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+			kind = typeInt64
+		case reflect.Float32:
+			kind = typeFloat32
+		case reflect.Float64:
+			kind = typeFloat64
+		case reflect.String:
+			kind = typeString
+		}
+	}
+	res, ok := types[kind]
+	if !ok {
+		return "", errs.NewErrUnsupportedColumnType(origin)
+	}
+	return res, nil
+}
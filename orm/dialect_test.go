@@ -0,0 +1,522 @@
+// create by chencanhua in 2023/9/26
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"orm_framework/orm/internal/errs"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPostgres_Build(t *testing.T) {
+	d := mysqlDB()
+	db, _ := OpenDB(d, WithPostgresDialect())
+	type PrimaryKeyModel struct {
+		Id   int64 `orm:"pk"`
+		Name string
+	}
+	type OrderDetail struct {
+		OrderId int
+		ItemId  int
+	}
+	testCases := []struct {
+		name      string
+		q         QueryBuilder
+		wantQuery *Query
+		wantErr   error
+	}{
+		{
+			name: "select",
+			q: NewSelector[TestModel](db).Where(C("Id").Eq(1), C("Age").In(18, 19)).
+				OrderBy(Desc("Id")).Limit(10).Offset(20),
+			wantQuery: &Query{
+				SQL: `SELECT * FROM "test_model" WHERE ("id" = $1) AND ("age" IN ($2,$3)) ` +
+					`ORDER BY "id" DESC LIMIT $4 OFFSET $5;`,
+				Args: []any{1, 18, 19, 10, 20},
+			},
+		},
+		{
+			// 没有指定冲突列的时候使用主键
+			name: "upsert primary key",
+			q: NewInserter[PrimaryKeyModel](db).Values(&PrimaryKeyModel{Id: 1, Name: "Tom"}).
+				OnDuplicateKey().Update(C("Name")),
+			wantQuery: &Query{
+				SQL: `INSERT INTO "primary_key_model"("id","name") VALUES ($1,$2) ` +
+					`ON CONFLICT("id") DO UPDATE SET "name"=excluded."name";`,
+				Args: []any{int64(1), "Tom"},
+			},
+		},
+		{
+			// 子查询的占位符和外层统一编号
+			name: "subquery",
+			q: func() QueryBuilder {
+				sub := NewSelector[OrderDetail](db).Select(C("OrderId")).
+					Where(C("ItemId").Eq(12)).AsSubquery("sub")
+				return NewSelector[TestModel](db).Where(C("Age").GT(18), C("Id").In(sub))
+			}(),
+			wantQuery: &Query{
+				SQL: `SELECT * FROM "test_model" WHERE ("age" > $1) AND ` +
+					`("id" IN (SELECT "order_id" FROM "order_detail" WHERE "item_id" = $2));`,
+				Args: []any{18, 12},
+			},
+		},
+		{
+			// 字符串中的 ? 不会被改写
+			name: "raw",
+			q: NewSelector[TestModel](db).
+				Where(Raw(`"first_name" = '?' AND "age" > ?`, 18).AsPredicate()),
+			wantQuery: &Query{
+				SQL:  `SELECT * FROM "test_model" WHERE "first_name" = '?' AND "age" > $1;`,
+				Args: []any{18},
+			},
+		},
+		{
+			name: "insert",
+			q: NewInserter[TestModel](db).Values(&TestModel{
+				Id:        1,
+				FirstName: "Deng",
+				Age:       18,
+			}),
+			wantQuery: &Query{
+				SQL:  `INSERT INTO "test_model"("id","first_name","age","last_name") VALUES ($1,$2,$3,$4);`,
+				Args: []any{1, "Deng", int8(18), (*sql.NullString)(nil)},
+			},
+		},
+		{
+			name: "upsert",
+			q: NewInserter[TestModel](db).Values(&TestModel{
+				Id:        1,
+				FirstName: "Deng",
+				Age:       18,
+			}).OnDuplicateKey().ConflictColumns("Id").
+				Update(C("FirstName"), Assign("Age", C("Age").Add(1))),
+			wantQuery: &Query{
+				SQL: `INSERT INTO "test_model"("id","first_name","age","last_name") VALUES ($1,$2,$3,$4) ` +
+					`ON CONFLICT("id") DO UPDATE SET "first_name"=excluded."first_name","age"="age" + $5;`,
+				Args: []any{1, "Deng", int8(18), (*sql.NullString)(nil), 1},
+			},
+		},
+		{
+			name: "upsert without conflict columns",
+			q: NewInserter[TestModel](db).Values(&TestModel{Id: 1}).
+				OnDuplicateKey().Update(C("FirstName")),
+			wantErr: errs.ErrUpsertConflictColumnsRequired,
+		},
+		{
+			name: "do nothing",
+			q: NewInserter[TestModel](db).Values(&TestModel{Id: 1}).
+				OnDuplicateKey().ConflictColumns("Id").DoNothing(),
+			wantQuery: &Query{
+				SQL: `INSERT INTO "test_model"("id","first_name","age","last_name") VALUES ($1,$2,$3,$4) ` +
+					`ON CONFLICT("id") DO NOTHING;`,
+				Args: []any{1, "", int8(0), (*sql.NullString)(nil)},
+			},
+		},
+		{
+			name: "returning",
+			q: NewInserter[TestModel](db).Columns("FirstName", "Age").
+				Values(&TestModel{FirstName: "Deng", Age: 18}).Returning("Id"),
+			wantQuery: &Query{
+				SQL:  `INSERT INTO "test_model"("first_name","age") VALUES ($1,$2) RETURNING "id";`,
+				Args: []any{"Deng", int8(18)},
+			},
+		},
+		{
+			name: "returning invalid column",
+			q: NewInserter[TestModel](db).
+				Values(&TestModel{FirstName: "Deng", Age: 18}).Returning("Invalid"),
+			wantErr: errs.NewErrUnknownField("Invalid"),
+		},
+		{
+			name: "update",
+			q: NewUpdater[TestModel](db).Set(Assign("FirstName", "Deng")).
+				Where(C("Id").Eq(1)),
+			wantQuery: &Query{
+				SQL:  `UPDATE "test_model" SET "first_name"=$1 WHERE "id" = $2;`,
+				Args: []any{"Deng", 1},
+			},
+		},
+		{
+			name: "delete",
+			q:    NewDeleter[TestModel](db).Where(C("Id").Eq(1)),
+			wantQuery: &Query{
+				SQL:  `DELETE FROM "test_model" WHERE "id" = $1;`,
+				Args: []any{1},
+			},
+		},
+		{
+			name:    "delete limit",
+			q:       NewDeleter[TestModel](db).Where(C("Id").Eq(1)).Limit(1),
+			wantErr: errs.NewErrUnsupportedDeleteLimit(1),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := tc.q.Build()
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantQuery, query)
+		})
+	}
+}
+
+func TestPostgres_Exec(t *testing.T) {
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	db, err := OpenDB(mockDB, WithPostgresDialect())
+	require.NoError(t, err)
+
+	rows := sqlmock.NewRows([]string{"id", "first_name", "age", "last_name"})
+	rows.AddRow([]byte("1"), []byte("Da"), []byte("18"), []byte("Ming"))
+	mock.ExpectQuery(`SELECT * FROM "test_model" WHERE "id" = $1;`).
+		WithArgs(1).WillReturnRows(rows)
+	res, err := NewSelector[TestModel](db).Where(C("Id").Eq(1)).Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &TestModel{
+		Id:        1,
+		FirstName: "Da",
+		Age:       18,
+		LastName:  &sql.NullString{Valid: true, String: "Ming"},
+	}, res)
+
+	mock.ExpectExec(`UPDATE "test_model" SET "age"=$1 WHERE "id" = $2;`).
+		WithArgs(19, 1).WillReturnResult(driver.RowsAffected(1))
+	affected, err := NewUpdater[TestModel](db).Set(Assign("Age", 19)).
+		Where(C("Id").Eq(1)).Exec(context.Background()).RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// bracketDialect 模拟第三方方言，只覆盖引号、占位符和分页
+type bracketDialect struct {
+	Dialect
+}
+
+func (b bracketDialect) Name() string {
+	return "bracket"
+}
+
+func (b bracketDialect) Quote(name string) string {
+	return "[" + name + "]"
+}
+
+func (b bracketDialect) BindVars(query string) string {
+	return strings.ReplaceAll(query, "?", ":p")
+}
+
+func (b bracketDialect) BuildLimitOffset(builder SelectBuilder, limit int, offset int) error {
+	_, _ = builder.WriteString(" ROWS ?")
+	builder.AddArgs(offset)
+	if limit > 0 {
+		_, _ = builder.WriteString(" TO ?")
+		builder.AddArgs(offset + limit)
+	}
+	return nil
+}
+
+func TestDialect_Custom(t *testing.T) {
+	db, err := OpenDB(mysqlDB(), WithDialect(bracketDialect{Dialect: MySQLDialect}))
+	require.NoError(t, err)
+	testCases := []struct {
+		name      string
+		q         QueryBuilder
+		wantQuery *Query
+		wantErr   error
+	}{
+		{
+			name: "select",
+			q:    NewSelector[TestModel](db).Where(C("Id").Eq(1)).Limit(10).Offset(20),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM [test_model] WHERE [id] = :p ROWS :p TO :p;",
+				Args: []any{1, 20, 30},
+			},
+		},
+		{
+			// 没有覆盖的部分使用嵌入的方言
+			name: "upsert",
+			q: NewInserter[TestModel](db).Values(&TestModel{Id: 1}).Columns("Id", "Age").
+				OnDuplicateKey().Update(C("Age")),
+			wantQuery: &Query{
+				SQL:  "INSERT INTO [test_model]([id],[age]) VALUES (:p,:p) ON DUPLICATE KEY UPDATE [age]=VALUES([age]);",
+				Args: []any{1, int8(0)},
+			},
+		},
+		{
+			name: "delete limit",
+			q:    NewDeleter[TestModel](db).Limit(1),
+			wantQuery: &Query{
+				SQL:  "DELETE FROM [test_model] LIMIT :p;",
+				Args: []any{1},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q, err := tc.q.Build()
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantQuery, q)
+		})
+	}
+}
+
+func TestDialect_ColumnType(t *testing.T) {
+	testCases := []struct {
+		name    string
+		dialect Dialect
+		val     any
+		want    string
+		wantErr error
+	}{
+		{name: "mysql int", dialect: MySQLDialect, val: int(1), want: "BIGINT"},
+		{name: "mysql bool", dialect: MySQLDialect, val: true, want: "TINYINT(1)"},
+		{name: "mysql pointer", dialect: MySQLDialect, val: new(int8), want: "TINYINT"},
+		{name: "mysql null string", dialect: MySQLDialect, val: sql.NullString{}, want: "VARCHAR(255)"},
+		{name: "mysql time", dialect: MySQLDialect, val: time.Time{}, want: "DATETIME"},
+		{name: "sqlite string", dialect: SQLLiteDialect, val: "", want: "TEXT"},
+		{name: "postgres bytes", dialect: PostgreSQLDialect, val: []byte{}, want: "BYTEA"},
+		{name: "postgres float64", dialect: PostgreSQLDialect, val: float64(1), want: "DOUBLE PRECISION"},
+		{
+			name:    "unsupported",
+			dialect: MySQLDialect,
+			val:     map[string]string{},
+			wantErr: errs.NewErrUnsupportedColumnType(reflect.TypeOf(map[string]string{})),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := tc.dialect.ColumnType(reflect.TypeOf(tc.val))
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.want, res)
+		})
+	}
+}
+
+func TestStandard_Build(t *testing.T) {
+	db, err := OpenDB(mysqlDB(), WithStandardDialect())
+	require.NoError(t, err)
+	testCases := []struct {
+		name      string
+		q         QueryBuilder
+		wantQuery *Query
+		wantErr   error
+	}{
+		{
+			name: "select",
+			q:    NewSelector[TestModel](db).Where(C("Id").Eq(1)).OrderBy(Asc("Age")).Limit(10).Offset(20),
+			wantQuery: &Query{
+				SQL:  `SELECT * FROM "test_model" WHERE "id" = ? ORDER BY "age" ASC OFFSET ? ROWS FETCH FIRST ? ROWS ONLY;`,
+				Args: []any{1, 20, 10},
+			},
+		},
+		{
+			name: "limit only",
+			q:    NewSelector[TestModel](db).Limit(10),
+			wantQuery: &Query{
+				SQL:  `SELECT * FROM "test_model" FETCH FIRST ? ROWS ONLY;`,
+				Args: []any{10},
+			},
+		},
+		{
+			name: "offset only",
+			q:    NewSelector[TestModel](db).Offset(20),
+			wantQuery: &Query{
+				SQL:  `SELECT * FROM "test_model" OFFSET ? ROWS;`,
+				Args: []any{20},
+			},
+		},
+		{
+			name: "insert",
+			q:    NewInserter[TestModel](db).Values(&TestModel{Id: 1, Age: 18}).Columns("Id", "Age"),
+			wantQuery: &Query{
+				SQL:  `INSERT INTO "test_model"("id","age") VALUES (?,?);`,
+				Args: []any{1, int8(18)},
+			},
+		},
+		{
+			name: "merge",
+			q: NewInserter[TestModel](db).Values(&TestModel{Id: 1, Age: 18}, &TestModel{Id: 2, Age: 19}).
+				Columns("Id", "Age").OnDuplicateKey().ConflictColumns("Id").
+				Update(C("Age"), Assign("FirstName", "Tom")),
+			wantQuery: &Query{
+				SQL: `MERGE INTO "test_model" USING (VALUES (?,?),(?,?)) AS "src"("id","age") ` +
+					`ON ("test_model"."id"="src"."id") ` +
+					`WHEN MATCHED THEN UPDATE SET "age"="src"."age","first_name"=? ` +
+					`WHEN NOT MATCHED THEN INSERT ("id","age") VALUES ("src"."id","src"."age");`,
+				Args: []any{1, int8(18), 2, int8(19), "Tom"},
+			},
+		},
+		{
+			name: "merge do nothing",
+			q: NewInserter[TestModel](db).Values(&TestModel{Id: 1, Age: 18}).
+				Columns("Id", "Age").OnDuplicateKey().ConflictColumns("Id", "Age").DoNothing(),
+			wantQuery: &Query{
+				SQL: `MERGE INTO "test_model" USING (VALUES (?,?)) AS "src"("id","age") ` +
+					`ON ("test_model"."id"="src"."id" AND "test_model"."age"="src"."age") ` +
+					`WHEN NOT MATCHED THEN INSERT ("id","age") VALUES ("src"."id","src"."age");`,
+				Args: []any{1, int8(18)},
+			},
+		},
+		{
+			name: "merge without conflict columns",
+			q: NewInserter[TestModel](db).Values(&TestModel{Id: 1}).
+				OnDuplicateKey().Update(C("Age")),
+			wantErr: errs.ErrUpsertConflictColumnsRequired,
+		},
+		{
+			name: "merge unknown conflict column",
+			q: NewInserter[TestModel](db).Values(&TestModel{Id: 1}).
+				OnDuplicateKey().ConflictColumns("Invalid").Update(C("Age")),
+			wantErr: errs.NewErrUnknownField("Invalid"),
+		},
+		{
+			name:    "returning",
+			q:       NewInserter[TestModel](db).Values(&TestModel{Id: 1}).Returning("Id", "Age"),
+			wantErr: errs.ErrUnsupportedReturning,
+		},
+		{
+			name:    "delete limit",
+			q:       NewDeleter[TestModel](db).Limit(1),
+			wantErr: errs.NewErrUnsupportedDeleteLimit(1),
+		},
+		{
+			name: "update",
+			q:    NewUpdater[TestModel](db).Set(Assign("Age", 18)).Where(C("Id").Eq(1)),
+			wantQuery: &Query{
+				SQL:  `UPDATE "test_model" SET "age"=? WHERE "id" = ?;`,
+				Args: []any{18, 1},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q, err := tc.q.Build()
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantQuery, q)
+		})
+	}
+}
+
+func TestSQLServer_Build(t *testing.T) {
+	db, err := OpenDB(mysqlDB(), WithSQLServerDialect())
+	require.NoError(t, err)
+	testCases := []struct {
+		name      string
+		q         QueryBuilder
+		wantQuery *Query
+		wantErr   error
+	}{
+		{
+			name: "select",
+			q: NewSelector[TestModel](db).Where(C("Id").Eq(1), C("Age").In(18, 19)).
+				OrderBy(Desc("Id")).Limit(10).Offset(20),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM [test_model] WHERE ([id] = @p1) AND ([age] IN (@p2,@p3)) " +
+					"ORDER BY [id] DESC OFFSET @p4 ROWS FETCH NEXT @p5 ROWS ONLY;",
+				Args: []any{1, 18, 19, 20, 10},
+			},
+		},
+		{
+			// 没有 ORDER BY 的时候需要补上
+			name: "limit without order by",
+			q:    NewSelector[TestModel](db).Limit(10),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM [test_model] ORDER BY (SELECT NULL) OFFSET @p1 ROWS FETCH NEXT @p2 ROWS ONLY;",
+				Args: []any{0, 10},
+			},
+		},
+		{
+			name: "offset only",
+			q:    NewSelector[TestModel](db).OrderBy(Asc("Age")).Offset(20),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM [test_model] ORDER BY [age] ASC OFFSET @p1 ROWS;",
+				Args: []any{20},
+			},
+		},
+		{
+			name: "no paging",
+			q:    NewSelector[TestModel](db).Select(C("Id"), Avg("Age").As("avg_age")).GroupBy(C("Id")),
+			wantQuery: &Query{
+				SQL: "SELECT [id],AVG([age]) AS [avg_age] FROM [test_model] GROUP BY [id];",
+			},
+		},
+		{
+			name: "insert",
+			q:    NewInserter[TestModel](db).Values(&TestModel{Id: 1, Age: 18}).Columns("Id", "Age"),
+			wantQuery: &Query{
+				SQL:  "INSERT INTO [test_model]([id],[age]) VALUES (@p1,@p2);",
+				Args: []any{1, int8(18)},
+			},
+		},
+		{
+			name: "output",
+			q: NewInserter[TestModel](db).Values(&TestModel{Age: 18}).
+				Columns("Age").Returning("Id", "FirstName"),
+			wantQuery: &Query{
+				SQL:  "INSERT INTO [test_model]([age]) OUTPUT INSERTED.[id],INSERTED.[first_name] VALUES (@p1);",
+				Args: []any{int8(18)},
+			},
+		},
+		{
+			name: "merge",
+			q: NewInserter[TestModel](db).Values(&TestModel{Id: 1, Age: 18}).
+				Columns("Id", "Age").OnDuplicateKey().ConflictColumns("Id").
+				Update(C("Age"), Assign("FirstName", "Tom")),
+			wantQuery: &Query{
+				SQL: "MERGE INTO [test_model] USING (VALUES (@p1,@p2)) AS [src]([id],[age]) " +
+					"ON ([test_model].[id]=[src].[id]) " +
+					"WHEN MATCHED THEN UPDATE SET [age]=[src].[age],[first_name]=@p3 " +
+					"WHEN NOT MATCHED THEN INSERT ([id],[age]) VALUES ([src].[id],[src].[age]);",
+				Args: []any{1, int8(18), "Tom"},
+			},
+		},
+		{
+			name: "merge without conflict columns",
+			q: NewInserter[TestModel](db).Values(&TestModel{Id: 1}).
+				OnDuplicateKey().DoNothing(),
+			wantErr: errs.ErrUpsertConflictColumnsRequired,
+		},
+		{
+			name: "update",
+			q:    NewUpdater[TestModel](db).Set(Assign("Age", 18)).Where(C("Id").Eq(1)),
+			wantQuery: &Query{
+				SQL:  "UPDATE [test_model] SET [age]=@p1 WHERE [id] = @p2;",
+				Args: []any{18, 1},
+			},
+		},
+		{
+			name:    "delete limit",
+			q:       NewDeleter[TestModel](db).Limit(1),
+			wantErr: errs.NewErrUnsupportedDeleteLimit(1),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q, err := tc.q.Build()
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantQuery, q)
+		})
+	}
+}
@@ -24,3 +24,74 @@ func Raw(raw string, args ...any) RawExpr {
 		args: args,
 	}
 }
+
+// MathExpr 算术表达式，例如 C("Age").Add(1)
+// 可以继续嵌套，也可以用在 SELECT、WHERE 和 SET 中
+type MathExpr struct {
+	left  Expression
+	op    op
+	right Expression
+	alias string
+}
+
+func (MathExpr) expr() {}
+
+func (MathExpr) selectable() {}
+
+func (m MathExpr) Add(val any) MathExpr {
+	return math(m, opADD, val)
+}
+
+func (m MathExpr) Sub(val any) MathExpr {
+	return math(m, opSUB, val)
+}
+
+func (m MathExpr) Multi(val any) MathExpr {
+	return math(m, opMULTI, val)
+}
+
+func (m MathExpr) Div(val any) MathExpr {
+	return math(m, opDIV, val)
+}
+
+// As 用在 SELECT 中的别名
+func (m MathExpr) As(alias string) MathExpr {
+	return MathExpr{
+		left:  m.left,
+		op:    m.op,
+		right: m.right,
+		alias: alias,
+	}
+}
+
+func (m MathExpr) Eq(arg any) Predicate {
+	return binary(m, opEQ, arg)
+}
+
+func (m MathExpr) NotEq(arg any) Predicate {
+	return binary(m, opNEQ, arg)
+}
+
+func (m MathExpr) LT(arg any) Predicate {
+	return binary(m, opLT, arg)
+}
+
+func (m MathExpr) LTEq(arg any) Predicate {
+	return binary(m, opLTEQ, arg)
+}
+
+func (m MathExpr) GT(arg any) Predicate {
+	return binary(m, opGT, arg)
+}
+
+func (m MathExpr) GTEq(arg any) Predicate {
+	return binary(m, opGTEQ, arg)
+}
+
+func math(left Expression, o op, val any) MathExpr {
+	return MathExpr{
+		left:  left,
+		op:    o,
+		right: exprOf(val),
+	}
+}
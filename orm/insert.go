@@ -7,6 +7,7 @@ import (
 	"github.com/valyala/bytebufferpool"
 	"orm_framework/orm/internal/errs"
 	"orm_framework/orm/model"
+	"reflect"
 )
 
 var _ QueryBuilder = &Inserter[any]{}
@@ -22,9 +23,7 @@ func NewInserter[T any](sess Session) *Inserter[T] {
 	return &Inserter[T]{
 		insertBuilder: insertBuilder{
 			builder: builder{
-				core:   c,
-				quoter: c.dialect.quoter(),
-				buffer: bytebufferpool.Get(),
+				core: c,
 			},
 		},
 		sess: sess,
@@ -42,6 +41,13 @@ func (i *Inserter[T]) Values(vals ...*T) *Inserter[T] {
 	return i
 }
 
+// Returning 指定 RETURNING 的字段，注意这里是结构体的元素
+// 不能和 OnDuplicateKey 一起使用，冲突的行不一定会出现在 RETURNING 中
+func (i *Inserter[T]) Returning(columns ...string) *Inserter[T] {
+	i.returning = columns
+	return i
+}
+
 func (i *Inserter[T]) OnDuplicateKey() *UpsertBuilder[T] {
 	return &UpsertBuilder[T]{
 		i: i,
@@ -49,6 +55,7 @@ func (i *Inserter[T]) OnDuplicateKey() *UpsertBuilder[T] {
 }
 
 func (i *Inserter[T]) Build() (*Query, error) {
+	i.reset()
 	defer bytebufferpool.Put(i.buffer)
 	if len(i.values) == 0 {
 		return nil, errs.ErrInsertZeroRow
@@ -58,74 +65,303 @@ func (i *Inserter[T]) Build() (*Query, error) {
 		return nil, err
 	}
 	i.model = m
-	i.writeString("INSERT INTO ")
-	i.quote(m.TableName)
-	i.writeString("(")
-	fields := m.Fields
-	if len(i.columns) != 0 {
-		fields = make([]*model.Field, 0, len(i.columns))
-		for _, goColumn := range i.columns {
-			field, ok := m.FieldMap[goColumn]
-			if !ok {
-				return nil, errs.NewErrUnknownField(goColumn)
+	fields, err := i.fields(m)
+	if err != nil {
+		return nil, err
+	}
+	if len(i.returning) > 0 && i.onDuplicate != nil {
+		return nil, errs.ErrUpsertReturning
+	}
+	rows := make([][]any, 0, len(i.values))
+	for _, val := range i.values {
+		c := i.Creator(val, i.model)
+		row := make([]any, 0, len(fields))
+		for _, field := range fields {
+			v, err := c.Field(field.GoName)
+			if err != nil {
+				return nil, err
 			}
-			fields = append(fields, field)
+			if v, err = field.ColumnValue(v); err != nil {
+				return nil, err
+			}
+			row = append(row, v)
 		}
+		rows = append(rows, row)
 	}
-	for index, field := range fields {
-		if index > 0 {
-			i.writeByte(',')
+
+	upsert := i.onDuplicate
+	// 没有指定冲突列的时候使用主键
+	if upsert != nil && len(upsert.conflictColumns) == 0 && len(m.PrimaryKeys) > 0 {
+		conflictColumns := make([]string, 0, len(m.PrimaryKeys))
+		for _, pk := range m.PrimaryKeys {
+			conflictColumns = append(conflictColumns, pk.GoName)
+		}
+		upsert = &Upsert{
+			assigns:         upsert.assigns,
+			conflictColumns: conflictColumns,
+			doNothing:       upsert.doNothing,
 		}
-		i.quote(field.ColName)
 	}
-	i.writeString(") VALUES ")
-	for vIndex, val := range i.values {
-		c := i.Creator(val, i.model)
-		if vIndex > 0 {
-			i.writeByte(',')
+
+	goColumns := i.returningColumns(m, fields)
+	returning := make([]string, 0, len(goColumns))
+	for _, goColumn := range goColumns {
+		field, err := m.FieldByName(goColumn)
+		if err != nil {
+			return nil, err
+		}
+		returning = append(returning, field.ColName)
+	}
+	od, isOutput := i.dialect.(OutputDialect)
+
+	if md, ok := i.dialect.(MergeDialect); ok && upsert != nil {
+		columns := make([]string, 0, len(fields))
+		for _, field := range fields {
+			columns = append(columns, field.ColName)
+		}
+		err = md.BuildMerge(&i.builder, &Merge{
+			table:   m.TableName,
+			columns: columns,
+			values:  rows,
+			upsert:  upsert,
+		})
+		if err != nil {
+			return nil, err
 		}
-		i.writeByte('(')
-		for fIndex, field := range fields {
-			if fIndex > 0 {
+	} else {
+		i.writeString("INSERT INTO ")
+		i.quote(m.TableName)
+		i.writeString("(")
+		for index, field := range fields {
+			if index > 0 {
 				i.writeByte(',')
 			}
-			i.writeByte('?')
-			v, err := c.Field(field.GoName)
+			i.quote(field.ColName)
+		}
+		i.writeByte(')')
+		if isOutput && len(returning) > 0 {
+			if err = od.BuildOutput(&i.builder, returning); err != nil {
+				return nil, err
+			}
+		}
+		i.writeString(" VALUES ")
+		buildValues(&i.builder, rows)
+
+		if upsert != nil {
+			err = i.dialect.BuildUpsert(&i.builder, upsert)
 			if err != nil {
 				return nil, err
 			}
-			i.addArgs(v)
 		}
-		i.writeByte(')')
 	}
 
-	if i.onDuplicate != nil {
-		err = i.dialect.buildOnUpsert(&i.builder, i.onDuplicate)
+	if len(returning) > 0 && !isOutput {
+		if i.dialect.SupportReturning() {
+			if err = i.dialect.BuildReturning(&i.builder, returning); err != nil {
+				return nil, err
+			}
+		} else if len(returning) > 1 || !lastInsertIdColumn(m, goColumns[0]) {
+			// 不支持 RETURNING 的时候只能通过 LastInsertId 回填自增列或者主键
+			return nil, errs.ErrUnsupportedReturning
+		}
+	}
+
+	return i.end(), nil
+}
+
+// fields 插入的列
+// 没有指定列的时候插入全部列，但是自增列在所有行都是零值的时候会跳过，交给数据库生成
+// 有默认值的列依旧会插入零值，需要使用数据库默认值的时候通过 Columns 排除
+func (i *Inserter[T]) fields(m *model.Model) ([]*model.Field, error) {
+	if len(i.columns) != 0 {
+		fields := make([]*model.Field, 0, len(i.columns))
+		for _, goColumn := range i.columns {
+			field, err := m.FieldByName(goColumn)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, field)
+		}
+		return fields, nil
+	}
+	fields := make([]*model.Field, 0, len(m.Fields))
+	for _, field := range m.Fields {
+		if field.AutoIncrement {
+			zero, err := i.allZero(m, field)
+			if err != nil {
+				return nil, err
+			}
+			if zero {
+				continue
+			}
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// allZero 所有插入的行中该字段是否都是零值
+func (i *Inserter[T]) allZero(m *model.Model, field *model.Field) (bool, error) {
+	for _, val := range i.values {
+		v, err := i.Creator(val, m).Field(field.GoName)
 		if err != nil {
-			return nil, err
+			return false, err
+		}
+		if v != nil && !reflect.ValueOf(v).IsZero() {
+			return false, nil
 		}
 	}
+	return true, nil
+}
 
-	i.writeByte(';')
-	return &Query{
-		SQL:  i.buffer.String(),
-		Args: i.args,
-	}, nil
+// returningColumns 需要回填的字段
+// 没有指定 Returning，并且自增列没有插入的时候，回填自增列
+// UPSERT 的时候无法知道哪些行是插入的，不回填自增列
+func (i *Inserter[T]) returningColumns(m *model.Model, fields []*model.Field) []string {
+	if len(i.returning) > 0 || m.AutoIncrement == nil {
+		return i.returning
+	}
+	if i.onDuplicate != nil {
+		return nil
+	}
+	for _, field := range fields {
+		if field == m.AutoIncrement {
+			return nil
+		}
+	}
+	return []string{m.AutoIncrement.GoName}
 }
 
+// Exec 执行插入
+// 指定了 Returning 或者模型有自增列的时候，会将数据库生成的数据回填到插入的实体中
+// 不支持 RETURNING 的方言则使用 LastInsertId 回填
 func (i *Inserter[T]) Exec(ctx context.Context) sql.Result {
+	var returning []string
+	if len(i.values) > 0 {
+		m, err := i.r.Get(i.values[0])
+		if err != nil {
+			return &Result{
+				err: err,
+			}
+		}
+		fields, err := i.fields(m)
+		if err != nil {
+			return &Result{
+				err: err,
+			}
+		}
+		returning = i.returningColumns(m, fields)
+	}
+	if len(returning) > 0 && i.dialect.SupportReturning() {
+		return i.execReturning(ctx, returning)
+	}
 	qc := &QueryContext{
 		Type:    "INSERT",
 		Builder: i,
 	}
 	result := exec(ctx, i.sess, i.core, qc)
 	if result.Result != nil {
+		res := result.Result.(sql.Result)
+		// UPSERT 时 LastInsertId 和冲突的行对不上，不能按照顺序回填
+		if result.Err == nil && len(returning) > 0 && i.onDuplicate == nil {
+			if err := i.setLastInsertId(res, returning[0]); err != nil {
+				return &Result{
+					err: err,
+				}
+			}
+		}
 		return &Result{
-			res: result.Result.(sql.Result),
-			err: nil,
+			res: res,
+			err: result.Err,
 		}
 	}
 	return &Result{
 		err: result.Err,
 	}
 }
+
+// execReturning 通过查询执行 INSERT ... RETURNING
+// 返回的行和插入的行顺序一致，依次回填
+// 返回了自增列的时候，最后一行回填的值作为 LastInsertId
+func (i *Inserter[T]) execReturning(ctx context.Context, returning []string) sql.Result {
+	qc := &QueryContext{
+		Type:    "INSERT",
+		Builder: i,
+	}
+	result := query(ctx, i.sess, i.core, qc, func(rows *sql.Rows) (any, error) {
+		m, err := i.r.Get(i.values[0])
+		if err != nil {
+			return nil, err
+		}
+		var res returningResult
+		for rows.Next() {
+			if res.rows >= int64(len(i.values)) {
+				return nil, errs.ErrTooManyReturnedRows
+			}
+			val := i.Creator(i.values[res.rows], m)
+			if err = val.SetColumns(rows); err != nil {
+				return nil, err
+			}
+			res.rows++
+			if m.AutoIncrement == nil {
+				continue
+			}
+			for _, col := range returning {
+				if col != m.AutoIncrement.GoName {
+					continue
+				}
+				id, err := val.Field(col)
+				if err != nil {
+					return nil, err
+				}
+				if res.id, res.hasId = toInt64(id); !res.hasId {
+					return nil, errs.NewErrInvalidFieldValue(id, "int64")
+				}
+			}
+		}
+		return res, nil
+	})
+	if result.Err != nil {
+		return &Result{
+			err: result.Err,
+		}
+	}
+	return &Result{
+		res: result.Result.(sql.Result),
+	}
+}
+
+// lastInsertIdColumn goColumn 是否可以通过 LastInsertId 回填
+// 只有自增列，或者唯一的主键才是 LastInsertId 对应的列
+func lastInsertIdColumn(m *model.Model, goColumn string) bool {
+	if m.AutoIncrement != nil {
+		return m.AutoIncrement.GoName == goColumn
+	}
+	return len(m.PrimaryKeys) == 1 && m.PrimaryKeys[0].GoName == goColumn
+}
+
+// setLastInsertId 将 LastInsertId 回填到 goColumn 字段
+// 批量插入时 MySQL 的 LastInsertId 是第一行的 id，后面的行依次递增，SQLite 则是最后一行的 id
+// 自增列的回填是尽力而为的，驱动不支持 LastInsertId 的时候跳过，只有指定了 Returning 才返回错误
+func (i *Inserter[T]) setLastInsertId(res sql.Result, goColumn string) error {
+	id, err := res.LastInsertId()
+	if err != nil {
+		if len(i.returning) > 0 {
+			return err
+		}
+		return nil
+	}
+	if d, ok := i.dialect.(lastRowIdDialect); ok && d.lastRowId() {
+		id -= int64(len(i.values) - 1)
+	}
+	m, err := i.r.Get(i.values[0])
+	if err != nil {
+		return err
+	}
+	for idx, val := range i.values {
+		if err = i.Creator(val, m).SetField(goColumn, id+int64(idx)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -3,6 +3,8 @@ package orm
 
 type inserterBuilderAttribute struct {
 	columns []string
+	// returning RETURNING 的字段，需要方言支持
+	returning []string
 }
 
 type insertBuilder struct {
@@ -24,6 +26,8 @@ type UpsertBuilder[T any] struct {
 type Upsert struct {
 	assigns         []Assignable
 	conflictColumns []string
+	// doNothing 冲突时什么也不做
+	doNothing bool
 }
 
 func (o *UpsertBuilder[T]) ConflictColumns(conflictColumns ...string) *UpsertBuilder[T] {
@@ -39,3 +43,54 @@ func (o *UpsertBuilder[T]) Update(assigns ...Assignable) *Inserter[T] {
 	}
 	return o.i
 }
+
+// DoNothing 冲突时什么也不做，同样是一个终结方法
+func (o *UpsertBuilder[T]) DoNothing() *Inserter[T] {
+	o.i.onDuplicate = &Upsert{
+		conflictColumns: o.conflictColumns,
+		doNothing:       true,
+	}
+	return o.i
+}
+
+// Assigns 冲突时的赋值语句
+func (u *Upsert) Assigns() []Assignable {
+	return u.assigns
+}
+
+// ConflictColumns 冲突的列，注意这里是结构体的元素
+func (u *Upsert) ConflictColumns() []string {
+	return u.conflictColumns
+}
+
+// DoNothing 冲突时是否什么也不做
+func (u *Upsert) DoNothing() bool {
+	return u.doNothing
+}
+
+// Merge 使用 MERGE 实现 UPSERT 时需要的信息
+type Merge struct {
+	table   string
+	columns []string
+	values  [][]any
+	upsert  *Upsert
+}
+
+// Table 表名
+func (m *Merge) Table() string {
+	return m.table
+}
+
+// Columns 插入的列名
+func (m *Merge) Columns() []string {
+	return m.columns
+}
+
+// Values 每一行插入的值，和 Columns 一一对应
+func (m *Merge) Values() [][]any {
+	return m.values
+}
+
+func (m *Merge) Upsert() *Upsert {
+	return m.upsert
+}
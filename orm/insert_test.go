@@ -16,6 +16,24 @@ import (
 func TestInserter_Build(t *testing.T) {
 	d := mysqlDB()
 	db, _ := OpenDB(d)
+	type DefaultModel struct {
+		Id     int64
+		Status int8 `orm:"default=1"`
+		Flag   bool `orm:"default=1"`
+	}
+	type BaseModel struct {
+		Id         int64 `orm:"pk"`
+		CreateTime int64
+	}
+	type EmbeddedModel struct {
+		BaseModel
+		Name string
+	}
+	type SerializerModel struct {
+		Id    int64
+		Tags  []string          `orm:"serializer=comma"`
+		Extra map[string]string `orm:"serializer=json"`
+	}
 	testCases := []struct {
 		name      string
 		q         QueryBuilder
@@ -116,6 +134,116 @@ func TestInserter_Build(t *testing.T) {
 				Args: []any{1, "Deng", int8(18), &sql.NullString{String: "Ming", Valid: true}, "zhangsan", 19},
 			},
 		},
+		{
+			name: "upsert math",
+			q: NewInserter[TestModel](db).Values(&TestModel{
+				Id:  1,
+				Age: 18,
+			}).OnDuplicateKey().Update(Assign("Age", C("Age").Add(1))),
+			wantQuery: &Query{
+				SQL: "INSERT INTO `test_model`(`id`,`first_name`,`age`,`last_name`) VALUES (?,?,?,?) " +
+					"ON DUPLICATE KEY UPDATE `age`=`age` + ?;",
+				Args: []any{1, "", int8(18), (*sql.NullString)(nil), 1},
+			},
+		},
+		{
+			// MySQL 没有 DO NOTHING
+			name: "upsert do nothing",
+			q: NewInserter[TestModel](db).Values(&TestModel{Id: 1}).
+				OnDuplicateKey().DoNothing(),
+			wantQuery: &Query{
+				SQL: "INSERT INTO `test_model`(`id`,`first_name`,`age`,`last_name`) VALUES (?,?,?,?) " +
+					"ON DUPLICATE KEY UPDATE `id`=`id`;",
+				Args: []any{1, "", int8(0), (*sql.NullString)(nil)},
+			},
+		},
+		{
+			// 不支持 RETURNING 的时候通过 LastInsertId 回填
+			name: "returning last insert id",
+			q: NewInserter[BaseModel](db).Values(&BaseModel{CreateTime: 100}).
+				Columns("CreateTime").Returning("Id"),
+			wantQuery: &Query{
+				SQL:  "INSERT INTO `base_model`(`create_time`) VALUES (?);",
+				Args: []any{int64(100)},
+			},
+		},
+		{
+			// LastInsertId 只能回填主键
+			name: "returning non key column",
+			q: NewInserter[BaseModel](db).Values(&BaseModel{Id: 1}).
+				Columns("Id").Returning("CreateTime"),
+			wantErr: errs.ErrUnsupportedReturning,
+		},
+		{
+			// 没有主键的时候不知道 LastInsertId 对应哪一列
+			name: "returning without primary key",
+			q: NewInserter[TestModel](db).Values(&TestModel{FirstName: "Tom"}).
+				Columns("FirstName").Returning("Id"),
+			wantErr: errs.ErrUnsupportedReturning,
+		},
+		{
+			name: "returning multiple columns",
+			q: NewInserter[TestModel](db).Values(&TestModel{Id: 1}).
+				Returning("Id", "Age"),
+			wantErr: errs.ErrUnsupportedReturning,
+		},
+		{
+			name: "returning unknown field",
+			q: NewInserter[TestModel](db).Values(&TestModel{Id: 1}).
+				Returning("Invalid"),
+			wantErr: errs.NewErrUnknownField("Invalid"),
+		},
+		{
+			// 嵌入的结构体展开为列
+			name: "embedded",
+			q: NewInserter[EmbeddedModel](db).Values(&EmbeddedModel{
+				BaseModel: BaseModel{Id: 1, CreateTime: 100}, Name: "Tom",
+			}).OnDuplicateKey().Update(C("Name")),
+			wantQuery: &Query{
+				SQL: "INSERT INTO `embedded_model`(`id`,`create_time`,`name`) VALUES (?,?,?) " +
+					"ON DUPLICATE KEY UPDATE `name`=VALUES(`name`);",
+				Args: []any{int64(1), int64(100), "Tom"},
+			},
+		},
+		{
+			// 写入的时候使用序列化之后的值
+			name: "serializer",
+			q: NewInserter[SerializerModel](db).Values(
+				&SerializerModel{Id: 1, Tags: []string{"a", "b"}, Extra: map[string]string{"k": "v"}},
+				&SerializerModel{Id: 2}),
+			wantQuery: &Query{
+				SQL:  "INSERT INTO `serializer_model`(`id`,`tags`,`extra`) VALUES (?,?,?),(?,?,?);",
+				Args: []any{int64(1), "a,b", `{"k":"v"}`, int64(2), "", nil},
+			},
+		},
+		{
+			name: "upsert assign serializer",
+			q: NewInserter[SerializerModel](db).Values(&SerializerModel{Id: 1}).
+				OnDuplicateKey().Update(Assign("Tags", []string{"a", "b"})),
+			wantQuery: &Query{
+				SQL: "INSERT INTO `serializer_model`(`id`,`tags`,`extra`) VALUES (?,?,?) " +
+					"ON DUPLICATE KEY UPDATE `tags`=?;",
+				Args: []any{int64(1), "", nil, "a,b"},
+			},
+		},
+		{
+			// 零值也是用户的意图，不能被数据库的默认值替代
+			name: "default zero",
+			q:    NewInserter[DefaultModel](db).Values(&DefaultModel{Id: 1, Flag: false}),
+			wantQuery: &Query{
+				SQL:  "INSERT INTO `default_model`(`id`,`status`,`flag`) VALUES (?,?,?);",
+				Args: []any{int64(1), int8(0), false},
+			},
+		},
+		{
+			// 使用数据库的默认值需要通过 Columns 排除
+			name: "default columns",
+			q:    NewInserter[DefaultModel](db).Values(&DefaultModel{Id: 1}).Columns("Id"),
+			wantQuery: &Query{
+				SQL:  "INSERT INTO `default_model`(`id`) VALUES (?);",
+				Args: []any{int64(1)},
+			},
+		},
 		{
 			name: "upsert column",
 			q: NewInserter[TestModel](db).Values(&TestModel{
@@ -197,6 +325,39 @@ func TestUpsert_SQLite3_Upsert(t *testing.T) {
 				Update(Assign("FirstName", "Da")),
 			wantErr: errs.NewErrUnknownField("Invalid"),
 		},
+		{
+			name: "do nothing",
+			q: NewInserter[TestModel](db).Values(&TestModel{Id: 1}).
+				OnDuplicateKey().ConflictColumns("Id").DoNothing(),
+			wantQuery: &Query{
+				SQL: "INSERT INTO `test_model`(`id`,`first_name`,`age`,`last_name`) VALUES (?,?,?,?) " +
+					"ON CONFLICT(`id`) DO NOTHING;",
+				Args: []any{1, "", int8(0), (*sql.NullString)(nil)},
+			},
+		},
+		{
+			// 冲突的行不会出现在 RETURNING 中
+			name: "do nothing returning",
+			q: NewInserter[TestModel](db).Values(&TestModel{Id: 1}).
+				OnDuplicateKey().ConflictColumns("Id").DoNothing().Returning("Id"),
+			wantErr: errs.ErrUpsertReturning,
+		},
+		{
+			name: "update returning",
+			q: NewInserter[TestModel](db).Values(&TestModel{Id: 1}).
+				OnDuplicateKey().ConflictColumns("Id").Update(C("FirstName")).Returning("Id"),
+			wantErr: errs.ErrUpsertReturning,
+		},
+		{
+			name: "returning",
+			q: NewInserter[TestModel](db).Values(&TestModel{Id: 1}).
+				Returning("Id", "FirstName"),
+			wantQuery: &Query{
+				SQL: "INSERT INTO `test_model`(`id`,`first_name`,`age`,`last_name`) VALUES (?,?,?,?) " +
+					"RETURNING `id`,`first_name`;",
+				Args: []any{1, "", int8(0), (*sql.NullString)(nil)},
+			},
+		},
 		{
 			// 使用原本插入的值
 			name: "upsert use insert value",
@@ -285,3 +446,307 @@ func TestInserter_Exec(t *testing.T) {
 		})
 	}
 }
+
+func TestInserter_Returning(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	sqlite, err := OpenDB(mockDB, WithSqlite3Dialect())
+	require.NoError(t, err)
+	testCases := []struct {
+		name       string
+		i          *Inserter[TestModel]
+		vals       []*TestModel
+		mockOrder  func(mock sqlmock.Sqlmock)
+		wantErr    error
+		wantVals   []*TestModel
+		wantAffect int64
+	}{
+		{
+			name: "returning",
+			i:    NewInserter[TestModel](sqlite).Columns("FirstName").Returning("Id", "Age"),
+			vals: []*TestModel{{FirstName: "Tom"}, {FirstName: "Jerry"}},
+			mockOrder: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id", "age"}).
+					AddRow(1, 18).AddRow(2, 19)
+				mock.ExpectQuery("INSERT INTO .* RETURNING .*").WillReturnRows(rows)
+			},
+			wantVals:   []*TestModel{{Id: 1, FirstName: "Tom", Age: 18}, {Id: 2, FirstName: "Jerry", Age: 19}},
+			wantAffect: 2,
+		},
+		{
+			name: "too many rows",
+			i:    NewInserter[TestModel](sqlite).Columns("FirstName").Returning("Id"),
+			vals: []*TestModel{{FirstName: "Tom"}},
+			mockOrder: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2)
+				mock.ExpectQuery("INSERT INTO .* RETURNING .*").WillReturnRows(rows)
+			},
+			wantErr: errs.ErrTooManyReturnedRows,
+		},
+		{
+			name: "query error",
+			i:    NewInserter[TestModel](sqlite).Columns("FirstName").Returning("Id"),
+			vals: []*TestModel{{FirstName: "Tom"}},
+			mockOrder: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("INSERT INTO .* RETURNING .*").WillReturnError(errors.New("db error"))
+			},
+			wantErr: errors.New("db error"),
+		},
+		{
+			// 冲突的行不会返回，按照顺序回填会错位
+			name: "upsert",
+			i: NewInserter[TestModel](sqlite).Columns("FirstName").Returning("Id").
+				OnDuplicateKey().ConflictColumns("FirstName").DoNothing(),
+			vals:      []*TestModel{{FirstName: "Tom"}, {FirstName: "Jerry"}},
+			mockOrder: func(mock sqlmock.Sqlmock) {},
+			wantErr:   errs.ErrUpsertReturning,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockOrder(mock)
+			res := tc.i.Values(tc.vals...).Exec(context.Background())
+			affected, err := res.RowsAffected()
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantAffect, affected)
+			assert.Equal(t, tc.wantVals, tc.vals)
+			// 没有返回自增列
+			_, err = res.LastInsertId()
+			assert.Equal(t, errs.ErrNoLastInsertId, err)
+		})
+	}
+}
+
+func TestInserter_LastInsertId(t *testing.T) {
+	type PrimaryKeyModel struct {
+		Id   int64 `orm:"pk"`
+		Name string
+		Age  int8
+	}
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	db, err := OpenDB(mockDB)
+	require.NoError(t, err)
+	testCases := []struct {
+		name      string
+		returning []string
+		vals      []*PrimaryKeyModel
+		mockOrder func(mock sqlmock.Sqlmock)
+		wantErr   error
+		wantVals  []*PrimaryKeyModel
+	}{
+		{
+			name:      "last insert id",
+			returning: []string{"Id"},
+			vals:      []*PrimaryKeyModel{{Name: "Tom"}, {Name: "Jerry"}},
+			mockOrder: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("INSERT INTO .*").WillReturnResult(sqlmock.NewResult(10, 2))
+			},
+			wantVals: []*PrimaryKeyModel{{Id: 10, Name: "Tom"}, {Id: 11, Name: "Jerry"}},
+		},
+		{
+			name:      "last insert id error",
+			returning: []string{"Id"},
+			vals:      []*PrimaryKeyModel{{Name: "Tom"}},
+			mockOrder: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("INSERT INTO .*").
+					WillReturnResult(sqlmock.NewErrorResult(errors.New("no id")))
+			},
+			wantErr: errors.New("no id"),
+		},
+		{
+			// LastInsertId 不能回填主键之外的列
+			name:      "non key column",
+			returning: []string{"Age"},
+			vals:      []*PrimaryKeyModel{{Name: "Tom"}},
+			mockOrder: func(mock sqlmock.Sqlmock) {},
+			wantErr:   errs.ErrUnsupportedReturning,
+			wantVals:  []*PrimaryKeyModel{{Name: "Tom"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockOrder(mock)
+			res := NewInserter[PrimaryKeyModel](db).Columns("Name").
+				Values(tc.vals...).Returning(tc.returning...).Exec(context.Background())
+			_, err := res.RowsAffected()
+			assert.Equal(t, tc.wantErr, err)
+			if tc.wantVals != nil {
+				assert.Equal(t, tc.wantVals, tc.vals)
+			}
+		})
+	}
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInserter_AutoIncrement(t *testing.T) {
+	type AutoIncrementModel struct {
+		Id   int64 `orm:"primary_key,auto_increment"`
+		Name string
+	}
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	mysql, err := OpenDB(mockDB)
+	require.NoError(t, err)
+	sqlite, err := OpenDB(mockDB, WithSqlite3Dialect())
+	require.NoError(t, err)
+	legacySqlite, err := OpenDB(mockDB, WithSqlite3LegacyDialect())
+	require.NoError(t, err)
+	sqlServer, err := OpenDB(mockDB, WithSQLServerDialect())
+	require.NoError(t, err)
+	standard, err := OpenDB(mockDB, WithStandardDialect())
+	require.NoError(t, err)
+	testCases := []struct {
+		name      string
+		db        *DB
+		upsert    bool
+		vals      []*AutoIncrementModel
+		mockOrder func(mock sqlmock.Sqlmock)
+		wantErr   error
+		wantVals  []*AutoIncrementModel
+		// wantId 通过 RETURNING 执行的插入的 LastInsertId
+		wantId int64
+	}{
+		{
+			// 自增列交给数据库生成，并按顺序回填
+			name: "mysql batch",
+			db:   mysql,
+			vals: []*AutoIncrementModel{{Name: "Tom"}, {Name: "Jerry"}, {Name: "Bob"}},
+			mockOrder: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("INSERT INTO `auto_increment_model`(`name`) VALUES (?),(?),(?);").
+					WithArgs("Tom", "Jerry", "Bob").
+					WillReturnResult(sqlmock.NewResult(10, 3))
+			},
+			wantVals: []*AutoIncrementModel{{Id: 10, Name: "Tom"}, {Id: 11, Name: "Jerry"}, {Id: 12, Name: "Bob"}},
+		},
+		{
+			// 用户指定了主键的时候不需要回填
+			name: "mysql explicit id",
+			db:   mysql,
+			vals: []*AutoIncrementModel{{Id: 3, Name: "Tom"}, {Name: "Jerry"}},
+			mockOrder: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("INSERT INTO `auto_increment_model`(`id`,`name`) VALUES (?,?),(?,?);").
+					WithArgs(int64(3), "Tom", int64(0), "Jerry").
+					WillReturnResult(sqlmock.NewResult(4, 2))
+			},
+			wantVals: []*AutoIncrementModel{{Id: 3, Name: "Tom"}, {Name: "Jerry"}},
+		},
+		{
+			// 支持 RETURNING 的方言直接使用 RETURNING
+			name: "sqlite returning",
+			db:   sqlite,
+			vals: []*AutoIncrementModel{{Name: "Tom"}, {Name: "Jerry"}},
+			mockOrder: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("INSERT INTO `auto_increment_model`(`name`) VALUES (?),(?) RETURNING `id`;").
+					WithArgs("Tom", "Jerry").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5).AddRow(6))
+			},
+			wantVals: []*AutoIncrementModel{{Id: 5, Name: "Tom"}, {Id: 6, Name: "Jerry"}},
+			wantId:   6,
+		},
+		{
+			// 3.35 之前的 SQLite 通过 LastInsertId 回填，LastInsertId 是最后一行的 id
+			name: "legacy sqlite batch",
+			db:   legacySqlite,
+			vals: []*AutoIncrementModel{{Name: "Tom"}, {Name: "Jerry"}, {Name: "Bob"}},
+			mockOrder: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("INSERT INTO `auto_increment_model`(`name`) VALUES (?),(?),(?);").
+					WithArgs("Tom", "Jerry", "Bob").
+					WillReturnResult(sqlmock.NewResult(12, 3))
+			},
+			wantVals: []*AutoIncrementModel{{Id: 10, Name: "Tom"}, {Id: 11, Name: "Jerry"}, {Id: 12, Name: "Bob"}},
+		},
+		{
+			// SQL Server 使用 OUTPUT 返回自增列
+			name: "sql server output",
+			db:   sqlServer,
+			vals: []*AutoIncrementModel{{Name: "Tom"}, {Name: "Jerry"}},
+			mockOrder: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("INSERT INTO [auto_increment_model]([name]) OUTPUT INSERTED.[id] VALUES (@p1),(@p2);").
+					WithArgs("Tom", "Jerry").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7).AddRow(8))
+			},
+			wantVals: []*AutoIncrementModel{{Id: 7, Name: "Tom"}, {Id: 8, Name: "Jerry"}},
+			wantId:   8,
+		},
+		{
+			// 驱动不支持 LastInsertId 的时候插入依旧成功，只是不回填
+			name: "last insert id unsupported",
+			db:   standard,
+			vals: []*AutoIncrementModel{{Name: "Tom"}},
+			mockOrder: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`INSERT INTO "auto_increment_model"("name") VALUES (?);`).
+					WithArgs("Tom").
+					WillReturnResult(sqlmock.NewErrorResult(errors.New("LastInsertId is not supported")))
+			},
+			wantVals: []*AutoIncrementModel{{Name: "Tom"}},
+		},
+		{
+			// UPSERT 的时候不知道哪些行是插入的，不回填
+			name:   "mysql upsert",
+			db:     mysql,
+			upsert: true,
+			vals:   []*AutoIncrementModel{{Name: "Tom"}, {Name: "Jerry"}},
+			mockOrder: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("INSERT INTO `auto_increment_model`(`name`) VALUES (?),(?) "+
+					"ON DUPLICATE KEY UPDATE `name`=VALUES(`name`);").
+					WithArgs("Tom", "Jerry").
+					WillReturnResult(sqlmock.NewResult(100, 3))
+			},
+			wantVals: []*AutoIncrementModel{{Name: "Tom"}, {Name: "Jerry"}},
+		},
+		{
+			name:   "sqlite upsert",
+			db:     sqlite,
+			upsert: true,
+			vals:   []*AutoIncrementModel{{Name: "Tom"}, {Name: "Jerry"}},
+			mockOrder: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("INSERT INTO `auto_increment_model`(`name`) VALUES (?),(?) "+
+					"ON CONFLICT(`name`) DO UPDATE SET `name`=excluded.`name`;").
+					WithArgs("Tom", "Jerry").
+					WillReturnResult(sqlmock.NewResult(100, 2))
+			},
+			wantVals: []*AutoIncrementModel{{Name: "Tom"}, {Name: "Jerry"}},
+		},
+		{
+			name:   "sql server merge",
+			db:     sqlServer,
+			upsert: true,
+			vals:   []*AutoIncrementModel{{Name: "Tom"}, {Name: "Jerry"}},
+			mockOrder: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("MERGE INTO [auto_increment_model] USING (VALUES (@p1),(@p2)) AS [src]([name]) "+
+					"ON ([auto_increment_model].[name]=[src].[name]) "+
+					"WHEN MATCHED THEN UPDATE SET [name]=[src].[name] "+
+					"WHEN NOT MATCHED THEN INSERT ([name]) VALUES ([src].[name]);").
+					WithArgs("Tom", "Jerry").
+					WillReturnResult(sqlmock.NewResult(100, 2))
+			},
+			wantVals: []*AutoIncrementModel{{Name: "Tom"}, {Name: "Jerry"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockOrder(mock)
+			i := NewInserter[AutoIncrementModel](tc.db).Values(tc.vals...)
+			if tc.upsert {
+				i = i.OnDuplicateKey().ConflictColumns("Name").Update(C("Name"))
+			}
+			// 只关心 Exec 本身是否成功，NewErrorResult 的 RowsAffected 同样会返回错误
+			res := i.Exec(context.Background()).(*Result)
+			assert.Equal(t, tc.wantErr, res.err)
+			if res.err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantVals, tc.vals)
+			if tc.wantId != 0 {
+				id, err := res.LastInsertId()
+				require.NoError(t, err)
+				assert.Equal(t, tc.wantId, id)
+			}
+		})
+	}
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -12,12 +12,23 @@ var (
 	// 看到这个 error 说明你输入了其它的东西
 	// 我们并不希望用户能够直接使用 err == ErrPointerOnly
 	// 所以放在我们的 internal 包里
-	ErrPointOnly = errors.New("orm: 只支持一级指针作为输入，例如 *User")
+	ErrPointOnly = errors.New("orm: 只支持结构体或者结构体的一级指针")
 
 	ErrNoRows = errors.New("orm: 未找到数据")
 
 	ErrTooManyReturnedColumns = errors.New("eorm: 过多列")
 	ErrInsertZeroRow          = errors.New("orm: 插入 0 行")
+	ErrNoUpdatedColumns       = errors.New("orm: 未指定更新的列")
+	ErrUpdateEntityRequired   = errors.New("orm: 使用 C() 更新时必须通过 Update 指定实体")
+	ErrUnsupportedReturning   = errors.New("orm: 当前方言不支持 RETURNING")
+	ErrTooManyReturnedRows    = errors.New("orm: RETURNING 返回的行数多于插入的行数")
+	ErrNoLastInsertId         = errors.New("orm: RETURNING 没有返回自增列，无法获得 LastInsertId")
+	// ErrUpsertReturning UPSERT 时冲突的行不一定出现在 RETURNING 中，无法按照顺序回填
+	ErrUpsertReturning = errors.New("orm: UPSERT 不支持 RETURNING")
+
+	ErrUpsertConflictColumnsRequired = errors.New("orm: 当前方言的 UPSERT 必须指定冲突列")
+	// ErrNamingStrategyWithRegistry 命名策略属于 Registry，自定义的 Registry 需要自己指定命名策略
+	ErrNamingStrategyWithRegistry = errors.New("orm: WithNamingStrategy 不能和 WithRegistry 一起使用")
 )
 
 // NewErrUnknownField 返回代表未知字段的错误
@@ -26,6 +37,11 @@ func NewErrUnknownField(fd string) error {
 	return fmt.Errorf("orm: 未知字段 %s", fd)
 }
 
+// NewErrIgnoredField 字段未导出或者被 orm:"-" 标记忽略，不能在查询中使用
+func NewErrIgnoredField(fd string) error {
+	return fmt.Errorf("orm: 字段 %s 未导出或者被 orm:\"-\" 忽略，不能映射到列", fd)
+}
+
 func NewErrUnknownColumn(fd string) error {
 	return fmt.Errorf("orm: 未知列名 %s", fd)
 }
@@ -52,6 +68,61 @@ func NewErrUnsupportedTable(table any) error {
 	return fmt.Errorf("orm: 不支持的TableReference类型 %v", table)
 }
 
+// NewErrUnsupportedDeleteLimit 当前方言不支持 DELETE ... LIMIT
+func NewErrUnsupportedDeleteLimit(limit int) error {
+	return fmt.Errorf("orm: 当前方言不支持 DELETE 语句使用 LIMIT %d", limit)
+}
+
+// NewErrUnsupportedUpsert 方言不支持该形式的 UPSERT
+func NewErrUnsupportedUpsert(dialect string) error {
+	return fmt.Errorf("orm: %s 方言不支持该形式的 UPSERT", dialect)
+}
+
+// NewErrInvalidFieldValue 值无法转换为字段的类型
+func NewErrInvalidFieldValue(val any, typ any) error {
+	return fmt.Errorf("orm: 无法将 %v 设置到 %v 类型的字段", val, typ)
+}
+
+// NewErrUnsettableEmbedded 字段所在的嵌入结构体指针未导出并且为 nil，反射无法为它赋值
+func NewErrUnsettableEmbedded(goName string) error {
+	return fmt.Errorf("orm: 字段 %s 所在的嵌入结构体指针未导出并且为 nil", goName)
+}
+
+// NewErrMultipleAutoIncrement 一个模型只能有一个自增列
+func NewErrMultipleAutoIncrement(first string, second string) error {
+	return fmt.Errorf("orm: 只能有一个自增列，%s 和 %s 都声明了 auto_increment", first, second)
+}
+
+// NewErrFieldConflict 嵌入结构体之后出现了同名字段
+func NewErrFieldConflict(goName string, col string) error {
+	return fmt.Errorf("orm: 字段 %s 重复，列 %s", goName, col)
+}
+
+// NewErrColumnConflict 不同的字段映射到了同一个列
+func NewErrColumnConflict(col string, first string, second string) error {
+	return fmt.Errorf("orm: 列 %s 冲突，字段 %s 和 %s 都映射到了该列", col, first, second)
+}
+
+// NewErrCircularEmbedding 结构体循环嵌入
+func NewErrCircularEmbedding(typ any) error {
+	return fmt.Errorf("orm: 结构体 %v 循环嵌入", typ)
+}
+
+// NewErrRegisterModel 注册模型失败
+func NewErrRegisterModel(typ any, err error) error {
+	return fmt.Errorf("orm: 注册模型 %v 失败: %w", typ, err)
+}
+
+// NewErrUnknownSerializer 标签中使用了没有注册的序列化方式
+func NewErrUnknownSerializer(name string) error {
+	return fmt.Errorf("orm: 未知的序列化方式 %s", name)
+}
+
+// NewErrUnsupportedColumnType 方言无法映射该 Go 类型
+func NewErrUnsupportedColumnType(typ any) error {
+	return fmt.Errorf("orm: 不支持的字段类型 %v", typ)
+}
+
 // 后面可以考虑支持错误码
 // func NewErrUnsupportedExpressionType(exp any) error {
 // 	return fmt.Errorf("orm-50001: 不支持的表达式 %v", exp)
@@ -0,0 +1,109 @@
+package gentest
+
+import (
+	"orm_framework/orm"
+
+	"database/sql"
+)
+
+const (
+	UserId = "Id"
+
+	UserName = "Name"
+
+	UserAge = "Age"
+
+	UserNickName = "NickName"
+
+	UserPicture = "Picture"
+
+	UserTags = "Tags"
+)
+
+func UserIdLT(val int64) orm.Predicate {
+	return orm.C("Id").LT(val)
+}
+
+func UserIdGT(val int64) orm.Predicate {
+	return orm.C("Id").GT(val)
+}
+
+func UserIdEq(val int64) orm.Predicate {
+	return orm.C("Id").Eq(val)
+}
+
+func UserNameLT(val string) orm.Predicate {
+	return orm.C("Name").LT(val)
+}
+
+func UserNameGT(val string) orm.Predicate {
+	return orm.C("Name").GT(val)
+}
+
+func UserNameEq(val string) orm.Predicate {
+	return orm.C("Name").Eq(val)
+}
+
+func UserAgeLT(val *int) orm.Predicate {
+	return orm.C("Age").LT(val)
+}
+
+func UserAgeGT(val *int) orm.Predicate {
+	return orm.C("Age").GT(val)
+}
+
+func UserAgeEq(val *int) orm.Predicate {
+	return orm.C("Age").Eq(val)
+}
+
+func UserNickNameLT(val *sql.NullString) orm.Predicate {
+	return orm.C("NickName").LT(val)
+}
+
+func UserNickNameGT(val *sql.NullString) orm.Predicate {
+	return orm.C("NickName").GT(val)
+}
+
+func UserNickNameEq(val *sql.NullString) orm.Predicate {
+	return orm.C("NickName").Eq(val)
+}
+
+func UserPictureLT(val []byte) orm.Predicate {
+	return orm.C("Picture").LT(val)
+}
+
+func UserPictureGT(val []byte) orm.Predicate {
+	return orm.C("Picture").GT(val)
+}
+
+func UserPictureEq(val []byte) orm.Predicate {
+	return orm.C("Picture").Eq(val)
+}
+
+func UserTagsLT(val []string) orm.Predicate {
+	return orm.C("Tags").LT(val)
+}
+
+func UserTagsGT(val []string) orm.Predicate {
+	return orm.C("Tags").GT(val)
+}
+
+func UserTagsEq(val []string) orm.Predicate {
+	return orm.C("Tags").Eq(val)
+}
+
+const (
+	AuditUserOperator = "Operator"
+)
+
+func AuditUserOperatorLT(val string) orm.Predicate {
+	return orm.C("Operator").LT(val)
+}
+
+func AuditUserOperatorGT(val string) orm.Predicate {
+	return orm.C("Operator").GT(val)
+}
+
+func AuditUserOperatorEq(val string) orm.Predicate {
+	return orm.C("Operator").Eq(val)
+}
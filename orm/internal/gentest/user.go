@@ -0,0 +1,24 @@
+// Package gentest create by chencanhua in 2023/10/15
+// orm_gen 生成代码的样例，user.gen.go 和 user_valuer.gen.go 都由 go generate 生成
+package gentest
+
+//go:generate go run orm_framework/gen/orm_gen -src user.go
+
+import "database/sql"
+
+type User struct {
+	Id       int64
+	Name     string
+	Age      *int
+	NickName *sql.NullString
+	Picture  []byte
+	Tags     []string `orm:"serializer=comma"`
+	Password string   `orm:"-"`
+	version  int
+}
+
+// AuditUser 有嵌入字段，不生成 Value
+type AuditUser struct {
+	User
+	Operator string
+}
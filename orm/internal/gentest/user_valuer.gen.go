@@ -0,0 +1,102 @@
+// Code generated by orm_gen. DO NOT EDIT.
+
+package gentest
+
+import (
+	"database/sql"
+	"orm_framework/orm"
+	"orm_framework/orm/model"
+)
+
+func init() {
+	orm.RegisterValue[User](NewUserValue)
+}
+
+// UserValue 不依赖反射的 orm.Valuer 实现
+type UserValue struct {
+	val     *User
+	meta    *model.Model
+	mapping orm.MappingMode
+}
+
+func NewUserValue(val *User, meta *model.Model) orm.Valuer {
+	return UserValue{val: val, meta: meta}
+}
+
+func (v UserValue) Field(name string) (any, error) {
+	switch name {
+	case "Id":
+		return v.val.Id, nil
+	case "Name":
+		return v.val.Name, nil
+	case "Age":
+		return v.val.Age, nil
+	case "NickName":
+		return v.val.NickName, nil
+	case "Picture":
+		return v.val.Picture, nil
+	case "Tags":
+		return v.val.Tags, nil
+	}
+	_, err := v.meta.FieldByName(name)
+	return nil, err
+}
+
+func (v UserValue) SetField(name string, val any) error {
+	ptr := v.address(name)
+	if ptr == nil {
+		_, err := v.meta.FieldByName(name)
+		return err
+	}
+	return orm.SetValue(ptr, val)
+}
+
+// SetColumns 列名是按照默认的命名策略生成的，不认识的列以及有 Serializer 的列交给 orm 处理
+func (v UserValue) SetColumns(rows *sql.Rows) error {
+	cs, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	dest := make([]any, len(cs))
+	for i, c := range cs {
+		switch c {
+		case "id":
+			dest[i] = &v.val.Id
+		case "name":
+			dest[i] = &v.val.Name
+		case "age":
+			dest[i] = &v.val.Age
+		case "nick_name":
+			dest[i] = &v.val.NickName
+		case "picture":
+			dest[i] = &v.val.Picture
+		}
+	}
+	return orm.ScanColumns(rows, cs, dest, v.meta, v.mapping, func(fd *model.Field) any {
+		return v.address(fd.GoName)
+	})
+}
+
+func (v UserValue) WithMapping(mapping orm.MappingMode) orm.Valuer {
+	v.mapping = mapping
+	return v
+}
+
+// address 返回字段的地址
+func (v UserValue) address(name string) any {
+	switch name {
+	case "Id":
+		return &v.val.Id
+	case "Name":
+		return &v.val.Name
+	case "Age":
+		return &v.val.Age
+	case "NickName":
+		return &v.val.NickName
+	case "Picture":
+		return &v.val.Picture
+	case "Tags":
+		return &v.val.Tags
+	}
+	return nil
+}
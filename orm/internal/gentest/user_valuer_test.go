@@ -0,0 +1,197 @@
+// create by chencanhua in 2023/10/15
+package gentest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"orm_framework/orm"
+	"orm_framework/orm/internal/errs"
+	"orm_framework/orm/internal/valuer"
+	"orm_framework/orm/model"
+	"testing"
+)
+
+var _ valuer.MappingValue = UserValue{}
+
+// 生成的 Value 和 unsafeValue 的行为保持一致
+func TestUserValue(t *testing.T) {
+	creators := map[string]valuer.Creator{
+		"generated": func(entity any, meta *model.Model) valuer.Value {
+			return NewUserValue(entity.(*User), meta)
+		},
+		"unsafe":  valuer.NewUnsafeValue,
+		"reflect": valuer.NewReflectValue,
+	}
+	age := 18
+	testCases := []struct {
+		name    string
+		mapping valuer.Mapping
+		cols    []string
+		row     []driver.Value
+
+		wantErr  error
+		wantUser *User
+	}{
+		{
+			name: "all columns",
+			cols: []string{"id", "name", "age", "nick_name", "picture", "tags"},
+			row:  []driver.Value{1, "Tom", 18, "T", []byte("pic"), "a,b"},
+			wantUser: &User{
+				Id:       1,
+				Name:     "Tom",
+				Age:      &age,
+				NickName: &sql.NullString{String: "T", Valid: true},
+				Picture:  []byte("pic"),
+				Tags:     []string{"a", "b"},
+			},
+		},
+		{
+			name:     "case insensitive",
+			cols:     []string{"ID", "Name"},
+			row:      []driver.Value{1, "Tom"},
+			wantUser: &User{Id: 1, Name: "Tom"},
+		},
+		{
+			name:    "too many columns",
+			cols:    []string{"id", "name", "age", "nick_name", "picture", "tags", "password"},
+			row:     []driver.Value{1, "Tom", 18, "T", []byte("pic"), "a,b", "123"},
+			wantErr: errs.ErrTooManyReturnedColumns,
+		},
+		{
+			name:    "unknown column",
+			cols:    []string{"id", "password"},
+			row:     []driver.Value{1, "123"},
+			wantErr: errs.NewErrUnknownColumn("password"),
+		},
+		{
+			name:     "lenient",
+			mapping:  valuer.MappingLenient,
+			cols:     []string{"id", "password"},
+			row:      []driver.Value{1, "123"},
+			wantUser: &User{Id: 1},
+		},
+	}
+
+	m, err := model.NewRegistry().Get(&User{})
+	require.NoError(t, err)
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = mockDB.Close() }()
+	for name, creator := range creators {
+		for _, tc := range testCases {
+			t.Run(name+" "+tc.name, func(t *testing.T) {
+				mock.ExpectQuery("SELECT XX").WillReturnRows(sqlmock.NewRows(tc.cols).AddRow(tc.row...))
+				rows, err := mockDB.Query("SELECT XX")
+				require.NoError(t, err)
+				require.True(t, rows.Next())
+
+				user := &User{}
+				err = valuer.WithMapping(creator(user, m), tc.mapping).SetColumns(rows)
+				assert.Equal(t, tc.wantErr, err)
+				if err != nil {
+					return
+				}
+				assert.Equal(t, tc.wantUser, user)
+			})
+		}
+
+		t.Run(name+" field", func(t *testing.T) {
+			user := &User{Id: 1, Name: "Tom"}
+			val := creator(user, m)
+			name, err := val.Field("Name")
+			require.NoError(t, err)
+			assert.Equal(t, "Tom", name)
+			_, err = val.Field("Password")
+			assert.Equal(t, errs.NewErrIgnoredField("Password"), err)
+			_, err = val.Field("Invalid")
+			assert.Equal(t, errs.NewErrUnknownField("Invalid"), err)
+
+			require.NoError(t, val.SetField("Id", 12))
+			assert.Equal(t, int64(12), user.Id)
+			assert.Equal(t, errs.NewErrUnknownField("Invalid"), val.SetField("Invalid", 1))
+		})
+	}
+}
+
+// 注册之后 DB 会自动使用生成的 Value
+func TestRegisterValue(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = mockDB.Close() }()
+	db, err := orm.OpenDB(mockDB)
+	require.NoError(t, err)
+
+	mock.ExpectQuery("SELECT .*").WillReturnRows(
+		sqlmock.NewRows([]string{"id", "name", "tags"}).AddRow(1, "Tom", "a,b"))
+	user, err := orm.NewSelector[User](db).Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &User{Id: 1, Name: "Tom", Tags: []string{"a", "b"}}, user)
+
+	mock.ExpectExec("INSERT INTO `user`.*").
+		WithArgs(int64(0), "Jerry", nil, nil, []byte(nil), "").
+		WillReturnResult(sqlmock.NewResult(3, 1))
+	_, err = orm.NewInserter[User](db).Values(&User{Name: "Jerry"}).Exec(context.Background()).RowsAffected()
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func BenchmarkSetColumns(b *testing.B) {
+	m, err := model.NewRegistry().Get(&User{})
+	require.NoError(b, err)
+	bench := func(b *testing.B, creator valuer.Creator) {
+		mockDB, mock, err := sqlmock.New()
+		require.NoError(b, err)
+		defer func() { _ = mockDB.Close() }()
+		mockRows := sqlmock.NewRows([]string{"id", "name", "age", "nick_name", "picture"})
+		row := []driver.Value{1, "Tom", 18, "T", []byte("pic")}
+		for i := 0; i < b.N; i++ {
+			mockRows.AddRow(row...)
+		}
+		mock.ExpectQuery("SELECT XX").WillReturnRows(mockRows)
+		rows, err := mockDB.Query("SELECT XX")
+		require.NoError(b, err)
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			rows.Next()
+			_ = creator(&User{}, m).SetColumns(rows)
+		}
+	}
+
+	b.Run("generated", func(b *testing.B) {
+		bench(b, func(entity any, meta *model.Model) valuer.Value {
+			return NewUserValue(entity.(*User), meta)
+		})
+	})
+	b.Run("unsafe", func(b *testing.B) {
+		bench(b, valuer.NewUnsafeValue)
+	})
+	b.Run("reflect", func(b *testing.B) {
+		bench(b, valuer.NewReflectValue)
+	})
+}
+
+func BenchmarkField(b *testing.B) {
+	m, err := model.NewRegistry().Get(&User{})
+	require.NoError(b, err)
+	user := &User{Id: 1, Name: "Tom"}
+	bench := func(b *testing.B, val valuer.Value) {
+		for i := 0; i < b.N; i++ {
+			_, _ = val.Field("Name")
+		}
+	}
+
+	b.Run("generated", func(b *testing.B) {
+		bench(b, NewUserValue(user, m))
+	})
+	b.Run("unsafe", func(b *testing.B) {
+		bench(b, valuer.NewUnsafeValue(user, m))
+	})
+	b.Run("reflect", func(b *testing.B) {
+		bench(b, valuer.NewReflectValue(user, m))
+	})
+}
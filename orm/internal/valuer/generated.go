@@ -0,0 +1,31 @@
+// create by chencanhua in 2023/10/15
+package valuer
+
+import (
+	"orm_framework/orm/model"
+	"reflect"
+	"sync"
+)
+
+// generated 代码生成的 Creator，key 是结构体指针的类型
+var generated sync.Map
+
+// Register 注册代码生成的 Creator，typ 是结构体指针的类型，例如 *User
+func Register(typ reflect.Type, c Creator) {
+	generated.Store(typ, c)
+}
+
+// WithGenerated 优先使用代码生成的 Creator，没有注册的类型使用 c
+func WithGenerated(c Creator) Creator {
+	return func(entity any, meta *model.Model) Value {
+		if g, ok := generated.Load(reflect.TypeOf(entity)); ok {
+			return g.(Creator)(entity, meta)
+		}
+		return c(entity, meta)
+	}
+}
+
+// SetValue 供代码生成的 Value 实现 SetField，ptr 是字段的地址
+func SetValue(ptr any, val any) error {
+	return setValue(reflect.ValueOf(ptr).Elem(), val)
+}
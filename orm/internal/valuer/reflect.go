@@ -8,9 +8,13 @@ import (
 	"reflect"
 )
 
+var anyType = reflect.TypeOf((*any)(nil)).Elem()
+
 type reflectValue struct {
 	val  reflect.Value
 	meta *model.Model
+	// mapping 结果集的映射方式
+	mapping Mapping
 }
 
 var _ Creator = NewReflectValue
@@ -25,7 +29,36 @@ func NewReflectValue(val interface{}, meta *model.Model) Value {
 }
 
 func (r reflectValue) Field(name string) (any, error) {
-	return r.val.FieldByName(name).Interface(), nil
+	fd, err := r.meta.FieldByName(name)
+	if err != nil {
+		return nil, err
+	}
+	val, err := r.field(fd, false)
+	if err != nil {
+		return nil, err
+	}
+	// 嵌入的结构体指针为 nil，字段自然是零值
+	if !val.IsValid() {
+		return reflect.Zero(fd.Type).Interface(), nil
+	}
+	return val.Interface(), nil
+}
+
+func (r reflectValue) SetField(name string, val any) error {
+	fd, err := r.meta.FieldByName(name)
+	if err != nil {
+		return err
+	}
+	dst, err := r.field(fd, true)
+	if err != nil {
+		return err
+	}
+	return setValue(dst, val)
+}
+
+func (r reflectValue) WithMapping(mapping Mapping) Value {
+	r.mapping = mapping
+	return r
 }
 
 func (r reflectValue) SetColumns(rows *sql.Rows) error {
@@ -33,33 +66,72 @@ func (r reflectValue) SetColumns(rows *sql.Rows) error {
 	if err != nil {
 		return err
 	}
-	if len(cs) > len(r.meta.FieldMap) {
-		return errs.ErrTooManyReturnedColumns
+	fields, err := columnFields(cs, r.meta, r.mapping)
+	if err != nil {
+		return err
 	}
 
 	vals := make([]any, 0, len(cs))
 	valsElems := make([]reflect.Value, 0, len(cs))
-	for _, c := range cs {
-		fieldInfo, ok := r.meta.ColumnMap[c]
-		if !ok {
-			return errs.NewErrUnknownColumn(c)
+	for _, fieldInfo := range fields {
+		typ := anyType
+		// 不认识的列和有 Serializer 的列先读取原始值
+		if fieldInfo != nil && fieldInfo.Serializer == nil {
+			typ = fieldInfo.Type
 		}
 		// 例如: fieldInfo.tOf = int, 那么这里value 是 *int
-		value := reflect.New(fieldInfo.Type)
+		value := reflect.New(typ)
 		vals = append(vals, value.Interface())
 		// 记得调用Elem，因为fieldInfo.tOf = int, 那么这里value 是 *int
 		valsElems = append(valsElems, value.Elem())
 	}
 
 	// 注意这里是vals...
-	rows.Scan(vals...)
+	if err = rows.Scan(vals...); err != nil {
+		return err
+	}
 
-	for index, c := range cs {
-		fieldInfo, ok := r.meta.ColumnMap[c]
-		if !ok {
-			return errs.NewErrUnknownColumn(c)
+	for index, fieldInfo := range fields {
+		if fieldInfo == nil {
+			continue
+		}
+		dst, err := r.field(fieldInfo, true)
+		if err != nil {
+			return err
 		}
-		r.val.FieldByName(fieldInfo.GoName).Set(valsElems[index])
+		if fieldInfo.Serializer != nil {
+			if err = fieldInfo.Serializer.Deserialize(valsElems[index].Interface(), dst); err != nil {
+				return err
+			}
+			continue
+		}
+		dst.Set(valsElems[index])
 	}
 	return nil
 }
+
+// field 按照索引路径返回字段
+// 嵌入结构体指针为 nil 的时候 alloc 为 true 会创建新的结构体，否则返回零值 reflect.Value
+func (r reflectValue) field(fd *model.Field, alloc bool) (reflect.Value, error) {
+	if len(fd.StructIndex) == 0 {
+		return r.val.FieldByName(fd.GoName), nil
+	}
+	val := r.val
+	for i, index := range fd.StructIndex {
+		if i > 0 && val.Kind() == reflect.Pointer {
+			if val.IsNil() {
+				if !alloc {
+					return reflect.Value{}, nil
+				}
+				// 未导出的嵌入指针无法通过反射赋值
+				if !val.CanSet() {
+					return reflect.Value{}, errs.NewErrUnsettableEmbedded(fd.GoName)
+				}
+				val.Set(reflect.New(val.Type().Elem()))
+			}
+			val = val.Elem()
+		}
+		val = val.Field(index)
+	}
+	return val, nil
+}
@@ -1,8 +1,59 @@
 // create by chencanhua in 2023/6/8
 package valuer
 
-import "testing"
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"orm_framework/orm/internal/errs"
+	"orm_framework/orm/model"
+	"testing"
+)
 
 func TestNewReflectValue(t *testing.T) {
 	testSetColumns(t, NewReflectValue)
 }
+
+func TestReflectValue_SetField(t *testing.T) {
+	testSetField(t, NewReflectValue)
+}
+
+func TestReflectValue_Lenient(t *testing.T) {
+	testLenient(t, NewReflectValue)
+}
+
+func TestReflectValue_Field(t *testing.T) {
+	testField(t, NewReflectValue)
+}
+
+func TestReflectValue_ScanError(t *testing.T) {
+	testScanError(t, NewReflectValue)
+}
+
+func TestReflectValue_Embedded(t *testing.T) {
+	testEmbedded(t, NewReflectValue)
+}
+
+type baseModel struct {
+	Id int64
+}
+
+type unexportedEmbeddedModel struct {
+	*baseModel
+	Name string
+}
+
+// 未导出的嵌入指针为 nil 的时候，反射无法创建结构体
+func TestReflectValue_UnexportedEmbedded(t *testing.T) {
+	entity := &unexportedEmbeddedModel{}
+	m, err := model.NewRegistry().Get(entity)
+	require.NoError(t, err)
+	val := NewReflectValue(entity, m)
+	id, err := val.Field("Id")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), id)
+	assert.Equal(t, errs.NewErrUnsettableEmbedded("Id"), val.SetField("Id", 1))
+
+	entity.baseModel = &baseModel{}
+	require.NoError(t, val.SetField("Id", 1))
+	assert.Equal(t, int64(1), entity.Id)
+}
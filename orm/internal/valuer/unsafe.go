@@ -3,7 +3,6 @@ package valuer
 
 import (
 	"database/sql"
-	"orm_framework/orm/internal/errs"
 	"orm_framework/orm/model"
 	"reflect"
 	"unsafe"
@@ -14,6 +13,8 @@ type unsafeValue struct {
 	address unsafe.Pointer
 	// meta 元数据
 	meta *model.Model
+	// mapping 结果集的映射方式
+	mapping Mapping
 }
 
 var _ Creator = NewUnsafeValue
@@ -26,36 +27,61 @@ func NewUnsafeValue(entity interface{}, meta *model.Model) Value {
 }
 
 func (u unsafeValue) Field(name string) (any, error) {
-	field, ok := u.meta.FieldMap[name]
-	if !ok {
-		return nil, errs.NewErrUnknownField(name)
+	field, err := u.meta.FieldByName(name)
+	if err != nil {
+		return nil, err
+	}
+	ptr := u.fieldAddress(field, false)
+	// 嵌入的结构体指针为 nil，字段自然是零值
+	if ptr == nil {
+		return reflect.Zero(field.Type).Interface(), nil
 	}
-	ptr := unsafe.Pointer(uintptr(u.address) + field.Offset)
 	// 这里NewAt是创建了指定地址的类型变量，不会修改对应地址的值
 	val := reflect.NewAt(field.Type, ptr).Elem()
 	return val.Interface(), nil
 }
 
+func (u unsafeValue) SetField(name string, val any) error {
+	field, err := u.meta.FieldByName(name)
+	if err != nil {
+		return err
+	}
+	ptr := u.fieldAddress(field, true)
+	return setValue(reflect.NewAt(field.Type, ptr).Elem(), val)
+}
+
+func (u unsafeValue) WithMapping(mapping Mapping) Value {
+	u.mapping = mapping
+	return u
+}
+
 func (u unsafeValue) SetColumns(rows *sql.Rows) error {
 	cs, err := rows.Columns()
 	if err != nil {
 		return err
 	}
-	if len(cs) > len(u.meta.FieldMap) {
-		return errs.ErrTooManyReturnedColumns
-	}
+	return ScanColumns(rows, cs, make([]any, len(cs)), u.meta, u.mapping, u.fieldPtr)
+}
+
+// fieldPtr 在字段的地址上创建指向字段的指针，例如 *int
+func (u unsafeValue) fieldPtr(fd *model.Field) any {
+	// 结构体的地址 + 对应字段在结构体中的偏移量
+	return reflect.NewAt(fd.Type, u.fieldAddress(fd, true)).Interface()
+}
 
-	colValues := make([]any, len(cs))
-	for i, c := range cs {
-		cm, ok := u.meta.ColumnMap[c]
-		if !ok {
-			return errs.NewErrUnknownColumn(c)
+// fieldAddress 返回字段的地址
+// 嵌入结构体指针中的字段需要先解引用，指针为 nil 的时候 alloc 为 true 会创建新的结构体，否则返回 nil
+func (u unsafeValue) fieldAddress(field *model.Field, alloc bool) unsafe.Pointer {
+	address := u.address
+	for _, p := range field.Pointers {
+		ptr := (*unsafe.Pointer)(unsafe.Pointer(uintptr(address) + p.Offset))
+		if *ptr == nil {
+			if !alloc {
+				return nil
+			}
+			*ptr = reflect.New(p.Type).UnsafePointer()
 		}
-		// 结构体的地址 + 对应字段在结构体中的偏移量
-		ptr := unsafe.Pointer(uintptr(u.address) + cm.Offset)
-		// 在特定地址创建值
-		val := reflect.NewAt(cm.Type, ptr)
-		colValues[i] = val.Interface()
+		address = *ptr
 	}
-	return rows.Scan(colValues...)
+	return unsafe.Pointer(uintptr(address) + field.Offset)
 }
@@ -6,8 +6,11 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"orm_framework/orm/internal/errs"
 	"orm_framework/orm/model"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestUnsafeValue_SetColumns(t *testing.T) {
@@ -71,6 +74,69 @@ func testSetColumns(t *testing.T, creator Creator) {
 				LastName: &sql.NullString{Valid: true, String: "Jerry"},
 			},
 		},
+
+		{
+			// 列名忽略大小写
+			name:   "case insensitive",
+			entity: &TestModel{},
+			rows: func() *sqlmock.Rows {
+				rows := sqlmock.NewRows([]string{"ID", "First_Name"})
+				rows.AddRow("1", "Tom")
+				return rows
+			},
+			wantEntity: &TestModel{
+				Id:        1,
+				FirstName: "Tom",
+			},
+		},
+
+		{
+			name:   "unknown column",
+			entity: &TestModel{},
+			rows: func() *sqlmock.Rows {
+				rows := sqlmock.NewRows([]string{"id", "nick_name"})
+				rows.AddRow("1", "Tom")
+				return rows
+			},
+			wantErr: errs.NewErrUnknownColumn("nick_name"),
+		},
+
+		{
+			name:   "too many columns",
+			entity: &TestModel{},
+			rows: func() *sqlmock.Rows {
+				rows := sqlmock.NewRows([]string{"id", "first_name", "age", "last_name", "nick_name"})
+				rows.AddRow("1", "Tom", "18", "Jerry", "T")
+				return rows
+			},
+			wantErr: errs.ErrTooManyReturnedColumns,
+		},
+		{
+			name:   "serializer",
+			entity: &SerializerModel{},
+			rows: func() *sqlmock.Rows {
+				rows := sqlmock.NewRows([]string{"id", "profile", "tags", "create_time"})
+				rows.AddRow("1", `{"nick":"Tom"}`, "a,b", int64(1696867200))
+				return rows
+			},
+			wantEntity: &SerializerModel{
+				Id:         1,
+				Profile:    &Profile{Nick: "Tom"},
+				Tags:       []string{"a", "b"},
+				CreateTime: time.Unix(1696867200, 0),
+			},
+		},
+
+		{
+			name:   "serializer null",
+			entity: &SerializerModel{Tags: []string{"a"}},
+			rows: func() *sqlmock.Rows {
+				rows := sqlmock.NewRows([]string{"id", "profile", "tags", "create_time"})
+				rows.AddRow("1", nil, nil, int64(0))
+				return rows
+			},
+			wantEntity: &SerializerModel{Id: 1},
+		},
 	}
 
 	r := model.NewRegistry()
@@ -102,6 +168,72 @@ func testSetColumns(t *testing.T, creator Creator) {
 	}
 }
 
+func TestUnsafeValue_SetField(t *testing.T) {
+	testSetField(t, NewUnsafeValue)
+}
+
+func testSetField(t *testing.T, creator Creator) {
+	testCases := []struct {
+		name  string
+		field string
+		val   any
+
+		wantErr    error
+		wantEntity *TestModel
+	}{
+		{
+			name:       "same type",
+			field:      "Id",
+			val:        int64(12),
+			wantEntity: &TestModel{Id: 12},
+		},
+		{
+			name:       "convertible",
+			field:      "Age",
+			val:        18,
+			wantEntity: &TestModel{Age: 18},
+		},
+		{
+			name:       "pointer",
+			field:      "LastName",
+			val:        &sql.NullString{Valid: true, String: "Jerry"},
+			wantEntity: &TestModel{LastName: &sql.NullString{Valid: true, String: "Jerry"}},
+		},
+		{
+			name:    "unknown field",
+			field:   "Invalid",
+			val:     12,
+			wantErr: errs.NewErrUnknownField("Invalid"),
+		},
+		{
+			name:    "invalid value",
+			field:   "Id",
+			val:     "abc",
+			wantErr: errs.NewErrInvalidFieldValue("abc", reflect.TypeOf(int64(0))),
+		},
+		{
+			name:    "nil",
+			field:   "Id",
+			val:     nil,
+			wantErr: errs.NewErrInvalidFieldValue(nil, reflect.TypeOf(int64(0))),
+		},
+	}
+	r := model.NewRegistry()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			entity := &TestModel{}
+			m, err := r.Get(entity)
+			require.NoError(t, err)
+			err = creator(entity, m).SetField(tc.field, tc.val)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantEntity, entity)
+		})
+	}
+}
+
 type TestModel struct {
 	Id int64
 	// ""
@@ -109,3 +241,256 @@ type TestModel struct {
 	Age       int8
 	LastName  *sql.NullString
 }
+
+func TestUnsafeValue_Lenient(t *testing.T) {
+	testLenient(t, NewUnsafeValue)
+}
+
+// testLenient 宽松模式下丢弃不认识的列
+func testLenient(t *testing.T, creator Creator) {
+	testCases := []struct {
+		name       string
+		rows       func() *sqlmock.Rows
+		wantEntity *TestModel
+	}{
+		{
+			name: "unknown column",
+			rows: func() *sqlmock.Rows {
+				rows := sqlmock.NewRows([]string{"id", "nick_name", "first_name"})
+				rows.AddRow("1", "T", "Tom")
+				return rows
+			},
+			wantEntity: &TestModel{Id: 1, FirstName: "Tom"},
+		},
+		{
+			// 表新增了列之后 SELECT *
+			name: "too many columns",
+			rows: func() *sqlmock.Rows {
+				rows := sqlmock.NewRows([]string{"id", "first_name", "age", "last_name", "nick_name", "create_time"})
+				rows.AddRow("1", "Tom", "18", "Jerry", "T", 100)
+				return rows
+			},
+			wantEntity: &TestModel{
+				Id:        1,
+				FirstName: "Tom",
+				Age:       18,
+				LastName:  &sql.NullString{Valid: true, String: "Jerry"},
+			},
+		},
+		{
+			name: "case insensitive",
+			rows: func() *sqlmock.Rows {
+				rows := sqlmock.NewRows([]string{"Id", "AGE", "Extra"})
+				rows.AddRow("1", "18", "x")
+				return rows
+			},
+			wantEntity: &TestModel{Id: 1, Age: 18},
+		},
+	}
+
+	r := model.NewRegistry()
+	m, err := r.Get(&TestModel{})
+	require.NoError(t, err)
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = mockDB.Close() }()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock.ExpectQuery("SELECT XX").WillReturnRows(tc.rows())
+			rows, err := mockDB.Query("SELECT XX")
+			require.NoError(t, err)
+			require.True(t, rows.Next())
+
+			entity := &TestModel{}
+			val := WithMapping(creator(entity, m), MappingLenient)
+			require.NoError(t, val.SetColumns(rows))
+			assert.Equal(t, tc.wantEntity, entity)
+		})
+	}
+}
+
+type Profile struct {
+	Nick string `json:"nick"`
+}
+
+type SerializerModel struct {
+	Id         int64
+	Profile    *Profile  `orm:"serializer=json"`
+	Tags       []string  `orm:"serializer=comma"`
+	CreateTime time.Time `orm:"serializer=unixtime"`
+}
+
+func TestUnsafeValue_Field(t *testing.T) {
+	testField(t, NewUnsafeValue)
+}
+
+func testField(t *testing.T, creator Creator) {
+	type IgnoredModel struct {
+		Id   int64
+		Name string `orm:"-"`
+	}
+	testCases := []struct {
+		name   string
+		entity any
+		field  string
+
+		wantErr error
+		wantVal any
+	}{
+		{
+			name:    "field",
+			entity:  &TestModel{Id: 12},
+			field:   "Id",
+			wantVal: int64(12),
+		},
+		{
+			name:    "pointer",
+			entity:  &TestModel{LastName: &sql.NullString{Valid: true, String: "Jerry"}},
+			field:   "LastName",
+			wantVal: &sql.NullString{Valid: true, String: "Jerry"},
+		},
+		{
+			name:    "unknown field",
+			entity:  &TestModel{},
+			field:   "Invalid",
+			wantErr: errs.NewErrUnknownField("Invalid"),
+		},
+		{
+			name:    "ignored field",
+			entity:  &IgnoredModel{},
+			field:   "Name",
+			wantErr: errs.NewErrIgnoredField("Name"),
+		},
+	}
+	r := model.NewRegistry()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := r.Get(tc.entity)
+			require.NoError(t, err)
+			val, err := creator(tc.entity, m).Field(tc.field)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantVal, val)
+		})
+	}
+}
+
+func TestUnsafeValue_ScanError(t *testing.T) {
+	testScanError(t, NewUnsafeValue)
+}
+
+// testScanError Scan 返回的错误需要返回给用户
+func testScanError(t *testing.T, creator Creator) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = mockDB.Close() }()
+	mock.ExpectQuery("SELECT XX").WillReturnRows(
+		sqlmock.NewRows([]string{"id", "first_name"}).AddRow("abc", "Tom"))
+	rows, err := mockDB.Query("SELECT XX")
+	require.NoError(t, err)
+	require.True(t, rows.Next())
+
+	entity := &TestModel{}
+	m, err := model.NewRegistry().Get(entity)
+	require.NoError(t, err)
+	err = creator(entity, m).SetColumns(rows)
+	assert.ErrorContains(t, err, "converting driver.Value type string (\"abc\") to a int64")
+}
+
+func TestUnsafeValue_Embedded(t *testing.T) {
+	testEmbedded(t, NewUnsafeValue)
+}
+
+func testEmbedded(t *testing.T, creator Creator) {
+	type BaseModel struct {
+		Id         int64
+		CreateTime int64
+	}
+	type EmbeddedModel struct {
+		BaseModel
+		Name string
+	}
+	type EmbeddedPtrModel struct {
+		Name string
+		*BaseModel
+	}
+	type AuditModel struct {
+		*BaseModel
+		Operator string
+	}
+	type NestedPtrModel struct {
+		Name string
+		*AuditModel
+	}
+	r := model.NewRegistry()
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = mockDB.Close() }()
+	query := func(rows *sqlmock.Rows) *sql.Rows {
+		mock.ExpectQuery("SELECT XX").WillReturnRows(rows)
+		res, err := mockDB.Query("SELECT XX")
+		require.NoError(t, err)
+		require.True(t, res.Next())
+		return res
+	}
+
+	t.Run("embedded", func(t *testing.T) {
+		entity := &EmbeddedModel{BaseModel: BaseModel{Id: 1}, Name: "Tom"}
+		m, err := r.Get(entity)
+		require.NoError(t, err)
+		val := creator(entity, m)
+		id, err := val.Field("Id")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), id)
+		require.NoError(t, val.SetField("CreateTime", 100))
+		assert.Equal(t, &EmbeddedModel{BaseModel: BaseModel{Id: 1, CreateTime: 100}, Name: "Tom"}, entity)
+
+		rows := query(sqlmock.NewRows([]string{"create_time", "id"}).AddRow(200, 2))
+		require.NoError(t, val.SetColumns(rows))
+		assert.Equal(t, &EmbeddedModel{BaseModel: BaseModel{Id: 2, CreateTime: 200}, Name: "Tom"}, entity)
+	})
+
+	t.Run("nil pointer", func(t *testing.T) {
+		entity := &EmbeddedPtrModel{Name: "Tom"}
+		m, err := r.Get(entity)
+		require.NoError(t, err)
+		val := creator(entity, m)
+		// 读取的时候不会创建结构体
+		id, err := val.Field("Id")
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), id)
+		assert.Nil(t, entity.BaseModel)
+
+		rows := query(sqlmock.NewRows([]string{"id", "name", "create_time"}).AddRow(12, "Jerry", 100))
+		require.NoError(t, val.SetColumns(rows))
+		assert.Equal(t, &EmbeddedPtrModel{Name: "Jerry", BaseModel: &BaseModel{Id: 12, CreateTime: 100}}, entity)
+	})
+
+	t.Run("set nil pointer", func(t *testing.T) {
+		entity := &EmbeddedPtrModel{}
+		m, err := r.Get(entity)
+		require.NoError(t, err)
+		require.NoError(t, creator(entity, m).SetField("Id", 3))
+		assert.Equal(t, &EmbeddedPtrModel{BaseModel: &BaseModel{Id: 3}}, entity)
+	})
+
+	t.Run("nested nil pointer", func(t *testing.T) {
+		entity := &NestedPtrModel{Name: "Tom"}
+		m, err := r.Get(entity)
+		require.NoError(t, err)
+		val := creator(entity, m)
+		ct, err := val.Field("CreateTime")
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), ct)
+		assert.Nil(t, entity.AuditModel)
+
+		rows := query(sqlmock.NewRows([]string{"id", "operator", "create_time"}).AddRow(12, "Jerry", 100))
+		require.NoError(t, val.SetColumns(rows))
+		assert.Equal(t, &NestedPtrModel{Name: "Tom", AuditModel: &AuditModel{
+			BaseModel: &BaseModel{Id: 12, CreateTime: 100},
+			Operator:  "Jerry",
+		}}, entity)
+	})
+}
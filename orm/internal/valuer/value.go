@@ -3,7 +3,9 @@ package valuer
 
 import (
 	"database/sql"
+	"orm_framework/orm/internal/errs"
 	"orm_framework/orm/model"
+	"reflect"
 )
 
 // Value 是对结构体实例的内部抽象
@@ -12,6 +14,111 @@ type Value interface {
 	Field(name string) (any, error)
 	// SetColumns 从数据库查询之后设置新值到Model
 	SetColumns(rows *sql.Rows) error
+	// SetField 设置字段的值，val 的类型可以转换为字段类型即可
+	// 例如将 LastInsertId 返回的 int64 设置到 int 类型的主键上
+	SetField(name string, val any) error
 }
 
 type Creator func(entity any, meta *model.Model) Value
+
+// Mapping 结果集中的列映射到字段的方式
+type Mapping uint8
+
+const (
+	// MappingStrict 结果集中有模型不认识的列时返回错误
+	MappingStrict Mapping = iota
+	// MappingLenient 丢弃模型不认识的列，例如 SELECT * 的时候表新增了列
+	MappingLenient
+)
+
+// MappingValue 支持切换映射方式的 Value，没有实现的 Value 只能使用 MappingStrict
+type MappingValue interface {
+	Value
+	// WithMapping 返回使用 mapping 的 Value
+	WithMapping(mapping Mapping) Value
+}
+
+// WithMapping 让 val 使用 mapping 映射结果集
+func WithMapping(val Value, mapping Mapping) Value {
+	if mv, ok := val.(MappingValue); ok {
+		return mv.WithMapping(mapping)
+	}
+	return val
+}
+
+// columnFields 找到每一列对应的字段，列名忽略大小写
+// 宽松模式下不认识的列对应的字段为 nil，需要读取到 sink 中丢弃
+func columnFields(cs []string, meta *model.Model, mapping Mapping) ([]*model.Field, error) {
+	if mapping == MappingStrict && len(cs) > len(meta.FieldMap) {
+		return nil, errs.ErrTooManyReturnedColumns
+	}
+	fields := make([]*model.Field, len(cs))
+	for i, c := range cs {
+		fd, ok := meta.FieldByColumn(c)
+		if !ok && mapping == MappingStrict {
+			return nil, errs.NewErrUnknownColumn(c)
+		}
+		fields[i] = fd
+	}
+	return fields, nil
+}
+
+// ScanColumns 读取当前行，代码生成的 Value 和 unsafeValue 共用
+// cs 是结果集的列，dest 中已经设置的列直接 Scan 到对应的地址，
+// 其余的列通过 meta 查找字段，address 返回字段的地址，例如 &u.Name，
+// 有 Serializer 的字段先读取原始值，再反序列化到字段上
+func ScanColumns(rows *sql.Rows, cs []string, dest []any, meta *model.Model,
+	mapping Mapping, address func(fd *model.Field) any) error {
+	if mapping == MappingStrict && len(cs) > len(meta.FieldMap) {
+		return errs.ErrTooManyReturnedColumns
+	}
+	// serialized 需要反序列化的字段，大多数模型都没有 Serializer，用到的时候再分配
+	var serialized []*model.Field
+	for i, c := range cs {
+		if dest[i] != nil {
+			continue
+		}
+		fd, ok := meta.FieldByColumn(c)
+		if !ok {
+			if mapping == MappingStrict {
+				return errs.NewErrUnknownColumn(c)
+			}
+			// 宽松模式下读取到 sink 中丢弃
+			dest[i] = new(any)
+			continue
+		}
+		if fd.Serializer == nil {
+			dest[i] = address(fd)
+			continue
+		}
+		if serialized == nil {
+			serialized = make([]*model.Field, len(cs))
+		}
+		serialized[i] = fd
+		dest[i] = new(any)
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
+
+	for i, fd := range serialized {
+		if fd == nil {
+			continue
+		}
+		dst := reflect.ValueOf(address(fd)).Elem()
+		if err := fd.Serializer.Deserialize(*dest[i].(*any), dst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setValue 将 val 转换为 dst 的类型之后设置到 dst
+func setValue(dst reflect.Value, val any) error {
+	v := reflect.ValueOf(val)
+	if !v.IsValid() || !v.Type().ConvertibleTo(dst.Type()) {
+		return errs.NewErrInvalidFieldValue(val, dst.Type())
+	}
+	dst.Set(v.Convert(dst.Type()))
+	return nil
+}
@@ -4,6 +4,7 @@ package model
 import (
 	"orm_framework/orm/internal/errs"
 	"reflect"
+	"strings"
 )
 
 type ModelOpt func(m *Model) error
@@ -14,6 +15,40 @@ type Model struct {
 	FieldMap  map[string]*Field
 	ColumnMap map[string]*Field
 	Fields    []*Field
+	// PrimaryKeys 主键，通过 orm:"primary_key" 或者 orm:"pk" 标记
+	PrimaryKeys []*Field
+	// AutoIncrement 自增列，一个模型最多只有一个
+	AutoIncrement *Field
+	// Indexes 索引名到列的映射，同名的列组成联合索引
+	Indexes map[string][]*Field
+	// IgnoredFields 被忽略的字段，包括未导出的字段以及 orm:"-" 标记的字段
+	IgnoredFields map[string]struct{}
+}
+
+// FieldByName 按照结构体字段名查找字段
+// 字段被忽略的时候返回的错误会说明原因，方便排查
+func (m *Model) FieldByName(name string) (*Field, error) {
+	if fd, ok := m.FieldMap[name]; ok {
+		return fd, nil
+	}
+	if _, ok := m.IgnoredFields[name]; ok {
+		return nil, errs.NewErrIgnoredField(name)
+	}
+	return nil, errs.NewErrUnknownField(name)
+}
+
+// FieldByColumn 按照列名查找字段，精确匹配失败的时候忽略大小写
+// 例如 SELECT * 返回的 ID 也能映射到 id 列上
+func (m *Model) FieldByColumn(col string) (*Field, bool) {
+	if fd, ok := m.ColumnMap[col]; ok {
+		return fd, true
+	}
+	for _, fd := range m.Fields {
+		if strings.EqualFold(fd.ColName, col) {
+			return fd, true
+		}
+	}
+	return nil, false
 }
 
 type Field struct {
@@ -21,14 +56,51 @@ type Field struct {
 	GoName  string
 	Type    reflect.Type
 	Offset  uintptr
+	// PrimaryKey 是否是主键
+	PrimaryKey bool
+	// AutoIncrement 是否自增
+	AutoIncrement bool
+	// Nullable 是否允许为 NULL
+	Nullable bool
+	// Unique 是否唯一
+	Unique bool
+	// Size 列的长度，0 代表使用方言的默认长度
+	Size int
+	// Default 列的默认值，原样输出到 DDL 中，空字符串代表没有默认值
+	Default string
+	// Index 所属的索引名
+	Index string
+	// StructIndex 嵌入结构体中的字段在顶层结构体中的索引路径，可以用于 reflect.Value.FieldByIndex
+	// 非嵌入的字段为 nil
+	StructIndex []int
+	// Pointers 访问嵌入字段需要经过的结构体指针，此时 Offset 是相对于最后一个指针指向的结构体
+	Pointers []*EmbeddedPointer
+	// Serializer 字段和列之间的转换，为 nil 的时候直接使用字段的值
+	Serializer Serializer
+}
+
+// EmbeddedPointer 嵌入的结构体指针
+type EmbeddedPointer struct {
+	// Offset 指针相对于上一级结构体的偏移量
+	Offset uintptr
+	// Type 指针指向的结构体类型
+	Type reflect.Type
+}
+
+// ignore 记录被忽略的字段
+func (m *Model) ignore(name string) {
+	if m.IgnoredFields == nil {
+		m.IgnoredFields = make(map[string]struct{}, 2)
+	}
+	m.IgnoredFields[name] = struct{}{}
 }
 
 // WithColumnName 支持自定义字段名
 func WithColumnName(field string, name string) ModelOpt {
 	return func(m *Model) error {
-		f, ok := m.FieldMap[field]
-		if !ok {
-			return errs.NewErrUnknownField(field)
+		f, err := m.FieldByName(field)
+		if err != nil {
+			return err
 		}
 		f.ColName = name
 		return nil
@@ -45,10 +117,41 @@ func WithTableName(tableName string) ModelOpt {
 
 // 我们支持的全部标签上的 key 都放在这里
 // 方便用户查找，和我们后期维护
+// eg: orm:"column=id,pk,auto_increment" orm:"size=64,default=0,index=idx_name"
 const (
-	tagKeyColumn = "column"
+	tagKeyColumn        = "column"
+	tagKeyPrimaryKey    = "primary_key"
+	tagKeyPK            = "pk"
+	tagKeyAutoIncrement = "auto_increment"
+	tagKeyNullable      = "nullable"
+	tagKeyUnique        = "unique"
+	tagKeySize          = "size"
+	tagKeyDefault       = "default"
+	tagKeyIndex         = "index"
+	tagKeySerializer    = "serializer"
+
+	// tagIgnore 忽略该字段，orm:"-"
+	tagIgnore = "-"
 )
 
+// tagFlags 不需要值的标签，例如 orm:"primary_key"
+var tagFlags = map[string]struct{}{
+	tagKeyPrimaryKey:    {},
+	tagKeyPK:            {},
+	tagKeyAutoIncrement: {},
+	tagKeyNullable:      {},
+	tagKeyUnique:        {},
+}
+
+// tagValues 需要值的标签，例如 orm:"column=id"
+var tagValues = map[string]struct{}{
+	tagKeyColumn:     {},
+	tagKeySize:       {},
+	tagKeyDefault:    {},
+	tagKeyIndex:      {},
+	tagKeySerializer: {},
+}
+
 type TableName interface {
 	TableName() string
 }
@@ -0,0 +1,111 @@
+// create by chencanhua in 2023/10/8
+package model
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NamingStrategy 命名策略，决定结构体名到表名，字段名到列名的映射
+// 标签中的 column，TableName 接口以及 WithTableName 的优先级更高
+type NamingStrategy interface {
+	TableName(structName string) string
+	ColumnName(fieldName string) string
+}
+
+var (
+	// UnderscoreNaming 默认的命名策略，每个大写字母前面都加下划线
+	// eg: UserID => user_i_d
+	UnderscoreNaming NamingStrategy = namingStrategy{table: underscoreName, column: underscoreName}
+	// SnakeCaseNaming 识别缩写的蛇形命名
+	// eg: UserID => user_id, HTTPServer => http_server
+	SnakeCaseNaming NamingStrategy = namingStrategy{table: snakeCase, column: snakeCase}
+	// AsIsNaming 原样使用结构体名和字段名
+	AsIsNaming NamingStrategy = namingStrategy{table: asIs, column: asIs}
+)
+
+// TablePrefix 在 ns 生成的表名前面加上前缀
+func TablePrefix(ns NamingStrategy, prefix string) NamingStrategy {
+	return namingStrategy{
+		table: func(name string) string {
+			return prefix + ns.TableName(name)
+		},
+		column: ns.ColumnName,
+	}
+}
+
+// TableSuffix 在 ns 生成的表名后面加上后缀
+func TableSuffix(ns NamingStrategy, suffix string) NamingStrategy {
+	return namingStrategy{
+		table: func(name string) string {
+			return ns.TableName(name) + suffix
+		},
+		column: ns.ColumnName,
+	}
+}
+
+// PluralTable 使用复数形式的表名，默认是单数
+// eg: User => users, Category => categories
+func PluralTable(ns NamingStrategy) NamingStrategy {
+	return namingStrategy{
+		table: func(name string) string {
+			return plural(ns.TableName(name))
+		},
+		column: ns.ColumnName,
+	}
+}
+
+type namingStrategy struct {
+	table  func(name string) string
+	column func(name string) string
+}
+
+func (n namingStrategy) TableName(structName string) string {
+	return n.table(structName)
+}
+
+func (n namingStrategy) ColumnName(fieldName string) string {
+	return n.column(fieldName)
+}
+
+func asIs(name string) string {
+	return name
+}
+
+// snakeCase 识别缩写的蛇形命名
+// 大写字母前面是小写字母或者数字，或者是连续大写字母的最后一个且后面跟着小写字母时加下划线
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	sb.Grow(len(name) + 4)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				prev := runes[i-1]
+				nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+				if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+					sb.WriteByte('_')
+				}
+			}
+			sb.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// plural 简单的英文复数规则
+func plural(name string) string {
+	switch {
+	case name == "":
+		return name
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"), strings.HasSuffix(name, "z"),
+		strings.HasSuffix(name, "ch"), strings.HasSuffix(name, "sh"):
+		return name + "es"
+	case strings.HasSuffix(name, "y") && len(name) > 1 && !strings.ContainsRune("aeiou", rune(name[len(name)-2])):
+		return name[:len(name)-1] + "ies"
+	default:
+		return name + "s"
+	}
+}
@@ -0,0 +1,145 @@
+// create by chencanhua in 2023/10/8
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNamingStrategy(t *testing.T) {
+	testCases := []struct {
+		name      string
+		ns        NamingStrategy
+		src       string
+		wantTable string
+		wantCol   string
+	}{
+		{
+			name:      "underscore",
+			ns:        UnderscoreNaming,
+			src:       "UserID",
+			wantTable: "user_i_d",
+			wantCol:   "user_i_d",
+		},
+		{
+			name:      "snake case acronym",
+			ns:        SnakeCaseNaming,
+			src:       "UserID",
+			wantTable: "user_id",
+			wantCol:   "user_id",
+		},
+		{
+			name:      "snake case leading acronym",
+			ns:        SnakeCaseNaming,
+			src:       "HTTPServer",
+			wantTable: "http_server",
+			wantCol:   "http_server",
+		},
+		{
+			name:      "snake case number",
+			ns:        SnakeCaseNaming,
+			src:       "Table1Name",
+			wantTable: "table1_name",
+			wantCol:   "table1_name",
+		},
+		{
+			name:      "snake case all upper",
+			ns:        SnakeCaseNaming,
+			src:       "ID",
+			wantTable: "id",
+			wantCol:   "id",
+		},
+		{
+			name:      "as is",
+			ns:        AsIsNaming,
+			src:       "UserID",
+			wantTable: "UserID",
+			wantCol:   "UserID",
+		},
+		{
+			// 前缀和后缀只作用于表名
+			name:      "prefix and suffix",
+			ns:        TableSuffix(TablePrefix(SnakeCaseNaming, "t_"), "_tab"),
+			src:       "UserID",
+			wantTable: "t_user_id_tab",
+			wantCol:   "user_id",
+		},
+		{
+			name:      "plural",
+			ns:        PluralTable(SnakeCaseNaming),
+			src:       "User",
+			wantTable: "users",
+			wantCol:   "user",
+		},
+		{
+			name:      "plural y",
+			ns:        PluralTable(SnakeCaseNaming),
+			src:       "Category",
+			wantTable: "categories",
+			wantCol:   "category",
+		},
+		{
+			name:      "plural vowel y",
+			ns:        PluralTable(SnakeCaseNaming),
+			src:       "Day",
+			wantTable: "days",
+			wantCol:   "day",
+		},
+		{
+			name:      "plural es",
+			ns:        PluralTable(SnakeCaseNaming),
+			src:       "OrderBox",
+			wantTable: "order_boxes",
+			wantCol:   "order_box",
+		},
+		{
+			// 先复数再加前缀
+			name:      "plural with prefix",
+			ns:        TablePrefix(PluralTable(SnakeCaseNaming), "t_"),
+			src:       "Address",
+			wantTable: "t_addresses",
+			wantCol:   "address",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantTable, tc.ns.TableName(tc.src))
+			assert.Equal(t, tc.wantCol, tc.ns.ColumnName(tc.src))
+		})
+	}
+}
+
+type namingUser struct {
+	UserID   int64
+	NickName string `orm:"column=nick"`
+}
+
+type customTableUser struct {
+	UserID int64
+}
+
+func (c *customTableUser) TableName() string {
+	return "custom_user"
+}
+
+func TestRegistry_NamingStrategy(t *testing.T) {
+	r := NewRegistry(WithNamingStrategy(TablePrefix(PluralTable(SnakeCaseNaming), "t_")))
+
+	m, err := r.Get(&namingUser{})
+	require.NoError(t, err)
+	assert.Equal(t, "t_naming_users", m.TableName)
+	assert.Equal(t, "user_id", m.FieldMap["UserID"].ColName)
+	// 标签的优先级更高
+	assert.Equal(t, "nick", m.FieldMap["NickName"].ColName)
+
+	// TableName 接口的优先级更高
+	m, err = r.Get(&customTableUser{})
+	require.NoError(t, err)
+	assert.Equal(t, "custom_user", m.TableName)
+
+	// WithTableName 的优先级更高
+	m, err = r.Register(&namingUser{}, WithTableName("user_tab"))
+	require.NoError(t, err)
+	assert.Equal(t, "user_tab", m.TableName)
+}
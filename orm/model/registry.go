@@ -2,41 +2,86 @@
 package model
 
 import (
+	"database/sql"
 	"orm_framework/orm/internal/errs"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"unicode"
 )
 
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// Registry 元数据注册中心
+// val 可以是结构体或者结构体的一级指针，两者共享同一份元数据
 type Registry interface {
 	Get(val any) (*Model, error)
 	Register(val any, opts ...ModelOpt) (*Model, error)
+	// MustRegister 注册失败的时候 panic，适合在启动的时候使用
+	MustRegister(val any, opts ...ModelOpt) *Model
+	// RegisterAll 在启动的时候预先注册全部模型，配置错误的模型在启动时就能发现
+	RegisterAll(vals ...any) error
+	// Models 已经注册的全部模型，按照表名排序
+	Models() []*Model
 }
 
 var _ Registry = &registry{}
 
 type registry struct {
 	models sync.Map
+	naming NamingStrategy
+	// serializers 通过 WithSerializer 注册的序列化方式
+	serializers map[string]Serializer
+	// typeSerializers 通过 WithTypeSerializer 注册的序列化方式
+	typeSerializers map[reflect.Type]Serializer
+}
+
+type RegistryOption func(r *registry)
+
+func NewRegistry(opts ...RegistryOption) Registry {
+	res := &registry{
+		naming: UnderscoreNaming,
+	}
+	for _, opt := range opts {
+		opt(res)
+	}
+	return res
 }
 
-func NewRegistry() Registry {
-	return &registry{}
+// WithNamingStrategy 指定命名策略，默认是 UnderscoreNaming
+func WithNamingStrategy(ns NamingStrategy) RegistryOption {
+	return func(r *registry) {
+		r.naming = ns
+	}
 }
 
 // Get 获取model数据
 func (r *registry) Get(val any) (*Model, error) {
-	tOf := reflect.TypeOf(val)
-	m, ok := r.models.Load(tOf)
-	if ok {
+	typ, err := modelType(val)
+	if err != nil {
+		return nil, err
+	}
+	if m, ok := r.models.Load(typ); ok {
 		return m.(*Model), nil
 	}
-	return r.Register(val)
+	m, err := r.parseModel(typ)
+	if err != nil {
+		return nil, err
+	}
+	// 并发解析同一个类型的时候，保证返回的是同一份元数据
+	res, _ := r.models.LoadOrStore(typ, m)
+	return res.(*Model), nil
 }
 
 // Register 元数据注册
 func (r *registry) Register(val any, opts ...ModelOpt) (*Model, error) {
-	model, err := r.parseModel(val)
+	typ, err := modelType(val)
+	if err != nil {
+		return nil, err
+	}
+	model, err := r.parseModel(typ)
 	if err != nil {
 		return nil, err
 	}
@@ -46,80 +91,247 @@ func (r *registry) Register(val any, opts ...ModelOpt) (*Model, error) {
 			return nil, err
 		}
 	}
-	r.models.Store(reflect.TypeOf(val), model)
+	r.models.Store(typ, model)
 	return model, nil
 }
 
+func (r *registry) MustRegister(val any, opts ...ModelOpt) *Model {
+	m, err := r.Register(val, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// RegisterAll 已经注册过的模型不会被覆盖，避免丢失 Register 时指定的 ModelOpt
+func (r *registry) RegisterAll(vals ...any) error {
+	for _, val := range vals {
+		if _, err := r.Get(val); err != nil {
+			return errs.NewErrRegisterModel(reflect.TypeOf(val), err)
+		}
+	}
+	return nil
+}
+
+func (r *registry) Models() []*Model {
+	var res []*Model
+	r.models.Range(func(key, value any) bool {
+		res = append(res, value.(*Model))
+		return true
+	})
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].TableName < res[j].TableName
+	})
+	return res
+}
+
+// modelType 统一使用结构体类型作为缓存的 key
+// 只允许结构体或者一级指针结构体
+func modelType(val any) (reflect.Type, error) {
+	typ := reflect.TypeOf(val)
+	if typ == nil {
+		return nil, errs.ErrPointOnly
+	}
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, errs.ErrPointOnly
+	}
+	return typ, nil
+}
+
 // parseTag 获取标签
 func (r *registry) parseTag(tag reflect.StructTag) (map[string]string, error) {
 	ormTag := tag.Get("orm")
 	if ormTag == "" {
 		return map[string]string{}, nil
 	}
-	// 这个初始化容量就是我们支持的 key 的数量，
-	// 现在只有一个，所以我们初始化为 1
-	res := make(map[string]string, 1)
+	res := make(map[string]string, len(tagFlags)+4)
 
 	pairs := strings.Split(ormTag, ",")
 	for _, pair := range pairs {
-		kv := strings.Split(pair, "=")
+		if _, ok := tagFlags[pair]; ok {
+			res[pair] = ""
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
 		if len(kv) != 2 {
 			return nil, errs.NewErrInvalidTagContent(pair)
 		}
+		// 拼写错误的 key 不能被悄悄忽略，例如 colum=id
+		if _, ok := tagValues[kv[0]]; !ok {
+			return nil, errs.NewErrInvalidTagContent(pair)
+		}
 		res[kv[0]] = kv[1]
 	}
 	return res, nil
 }
 
-// parseModel 根据输入的entity，返回model数据
-func (r *registry) parseModel(entity any) (*Model, error) {
-	tOf := reflect.TypeOf(entity)
-	// 只允许一级指针结构体
-	if tOf.Kind() != reflect.Pointer || tOf.Elem().Kind() != reflect.Struct {
-		return nil, errs.ErrPointOnly
-	}
-	tOf = tOf.Elem()
+// parseModel 根据结构体类型，返回model数据
+func (r *registry) parseModel(tOf reflect.Type) (*Model, error) {
 	numField := tOf.NumField()
-	fieldMap := map[string]*Field{}
-	columnMap := map[string]*Field{}
-	fields := make([]*Field, 0, numField)
-	for index := 0; index < numField; index++ {
-		f := tOf.Field(index)
-		tagMap, err := r.parseTag(f.Tag)
-		if err != nil {
-			return nil, err
+	m := &Model{
+		FieldMap:  make(map[string]*Field, numField),
+		ColumnMap: make(map[string]*Field, numField),
+		Fields:    make([]*Field, 0, numField),
+	}
+	if err := r.parseFields(m, tOf, embedding{}); err != nil {
+		return nil, err
+	}
+
+	// 自定义表名，指针上同样包含了结构体的方法
+	if tn, ok := reflect.New(tOf).Interface().(TableName); ok {
+		m.TableName = tn.TableName()
+	}
+
+	if m.TableName == "" {
+		m.TableName = r.naming.TableName(tOf.Name())
+	}
+	return m, nil
+}
+
+// embedding 解析嵌入结构体时，从顶层结构体到当前结构体的路径
+type embedding struct {
+	index    []int
+	offset   uintptr
+	pointers []*EmbeddedPointer
+	// types 路径上的结构体，用于发现循环嵌入
+	types []reflect.Type
+}
+
+// parseFields 解析 typ 的字段，匿名嵌入的结构体以及结构体指针会被展开
+func (r *registry) parseFields(m *Model, typ reflect.Type, parent embedding) error {
+	for _, t := range parent.types {
+		if t == typ {
+			return errs.NewErrCircularEmbedding(typ)
 		}
-		columnName := tagMap[tagKeyColumn]
-		if columnName == "" {
-			columnName = underscoreName(f.Name)
+	}
+	parent.types = append(append(make([]reflect.Type, 0, len(parent.types)+1), parent.types...), typ)
+	for index := 0; index < typ.NumField(); index++ {
+		f := typ.Field(index)
+		if f.Tag.Get("orm") == tagIgnore {
+			m.ignore(f.Name)
+			continue
 		}
-		fieldInfo := &Field{
-			ColName: columnName,
-			GoName:  f.Name,
-			Type:    f.Type,
-			Offset:  f.Offset,
+		if f.Anonymous {
+			if embedded, typ, ok := r.embed(parent, f, index); ok {
+				if err := r.parseFields(m, typ, embedded); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		// 未导出的字段不映射到列
+		if !f.IsExported() {
+			m.ignore(f.Name)
+			continue
+		}
+		if err := r.parseField(m, f, index, parent); err != nil {
+			return err
 		}
-		fieldMap[f.Name] = fieldInfo
-		columnMap[columnName] = fieldInfo
-		fields = append(fields, fieldInfo)
 	}
+	return nil
+}
 
-	// 自定义表名
-	var tableName string
-	if tn, ok := entity.(TableName); ok {
-		tableName = tn.TableName()
+// embed 判断 f 是否需要展开，需要的话返回 f 内部字段的路径以及嵌入的结构体类型
+// 实现了 sql.Scanner 的结构体会被当做一个列，例如 sql.NullString
+func (r *registry) embed(parent embedding, f reflect.StructField, index int) (embedding, reflect.Type, bool) {
+	typ := f.Type
+	isPtr := typ.Kind() == reflect.Pointer
+	if isPtr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct || reflect.PointerTo(typ).Implements(scannerType) {
+		return embedding{}, nil, false
 	}
+	res := embedding{
+		index:    append(append(make([]int, 0, len(parent.index)+1), parent.index...), index),
+		offset:   parent.offset + f.Offset,
+		pointers: parent.pointers,
+		types:    parent.types,
+	}
+	if isPtr {
+		// 经过指针之后，偏移量从指针指向的结构体重新计算
+		res.pointers = append(append(make([]*EmbeddedPointer, 0, len(parent.pointers)+1), parent.pointers...),
+			&EmbeddedPointer{Offset: res.offset, Type: typ})
+		res.offset = 0
+	}
+	return res, typ, true
+}
 
-	if tableName == "" {
-		tableName = underscoreName(tOf.Name())
+// parseField 解析单个字段，并检查列名和字段名是否冲突
+func (r *registry) parseField(m *Model, f reflect.StructField, index int, parent embedding) error {
+	tagMap, err := r.parseTag(f.Tag)
+	if err != nil {
+		return err
+	}
+	columnName := tagMap[tagKeyColumn]
+	if columnName == "" {
+		columnName = r.naming.ColumnName(f.Name)
+	}
+	fieldInfo := &Field{
+		ColName: columnName,
+		GoName:  f.Name,
+		Type:    f.Type,
+		Offset:  parent.offset + f.Offset,
+	}
+	// 只有嵌入结构体中的字段需要记录路径
+	if len(parent.index) > 0 {
+		fieldInfo.StructIndex = append(append(make([]int, 0, len(parent.index)+1), parent.index...), index)
+		fieldInfo.Pointers = parent.pointers
+	}
+	if err = r.parseFieldTag(fieldInfo, tagMap); err != nil {
+		return err
+	}
+	if fieldInfo.Serializer, err = r.serializer(tagMap[tagKeySerializer], f.Type); err != nil {
+		return err
+	}
+	if fd, ok := m.FieldMap[f.Name]; ok {
+		return errs.NewErrFieldConflict(fd.GoName, columnName)
 	}
+	if fd, ok := m.ColumnMap[columnName]; ok {
+		return errs.NewErrColumnConflict(columnName, fd.GoName, f.Name)
+	}
+	if fieldInfo.PrimaryKey {
+		m.PrimaryKeys = append(m.PrimaryKeys, fieldInfo)
+	}
+	if fieldInfo.Index != "" {
+		if m.Indexes == nil {
+			m.Indexes = make(map[string][]*Field, 2)
+		}
+		m.Indexes[fieldInfo.Index] = append(m.Indexes[fieldInfo.Index], fieldInfo)
+	}
+	if _, ok := tagMap[tagKeyAutoIncrement]; ok {
+		if m.AutoIncrement != nil {
+			return errs.NewErrMultipleAutoIncrement(m.AutoIncrement.GoName, f.Name)
+		}
+		fieldInfo.AutoIncrement = true
+		m.AutoIncrement = fieldInfo
+	}
+	m.FieldMap[f.Name] = fieldInfo
+	m.ColumnMap[columnName] = fieldInfo
+	m.Fields = append(m.Fields, fieldInfo)
+	return nil
+}
 
-	return &Model{
-		TableName: tableName,
-		FieldMap:  fieldMap,
-		ColumnMap: columnMap,
-		Fields:    fields,
-	}, nil
+// parseFieldTag 将标签上的属性设置到字段上
+func (r *registry) parseFieldTag(fd *Field, tagMap map[string]string) error {
+	_, pk := tagMap[tagKeyPrimaryKey]
+	_, short := tagMap[tagKeyPK]
+	fd.PrimaryKey = pk || short
+	_, fd.Nullable = tagMap[tagKeyNullable]
+	_, fd.Unique = tagMap[tagKeyUnique]
+	fd.Default = tagMap[tagKeyDefault]
+	fd.Index = tagMap[tagKeyIndex]
+	if size, ok := tagMap[tagKeySize]; ok {
+		val, err := strconv.Atoi(size)
+		if err != nil || val <= 0 {
+			return errs.NewErrInvalidTagContent(tagKeySize + "=" + size)
+		}
+		fd.Size = val
+	}
+	return nil
 }
 
 // underscoreName 驼峰转字符串命名
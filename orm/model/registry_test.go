@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"orm_framework/orm/internal/errs"
 	"reflect"
 	"testing"
@@ -94,6 +95,11 @@ func TestModelWithColumnName(t *testing.T) {
 }
 
 func TestRegistry_get(t *testing.T) {
+	// 嵌入指针的用例需要在期望结果中引用同一个类型
+	type EmbeddedBase struct {
+		Id         int64
+		CreateTime int64
+	}
 	type TestModel struct {
 		Id        int64
 		FirstName string
@@ -109,9 +115,9 @@ func TestRegistry_get(t *testing.T) {
 		cacheSize int
 	}{
 		{
-			name:      "test Model",
-			entity:    TestModel{},
-			wantError: errors.New("orm: 只支持一级指针作为输入，例如 *User"),
+			name:      "nil",
+			entity:    nil,
+			wantError: errors.New("orm: 只支持结构体或者结构体的一级指针"),
 		},
 		{
 			name:   "point struct",
@@ -208,22 +214,22 @@ func TestRegistry_get(t *testing.T) {
 				val := &TestModel{}
 				return &val
 			}(),
-			wantError: errors.New("orm: 只支持一级指针作为输入，例如 *User"),
+			wantError: errors.New("orm: 只支持结构体或者结构体的一级指针"),
 		},
 		{
 			name:      "map",
 			entity:    map[string]string{},
-			wantError: errors.New("orm: 只支持一级指针作为输入，例如 *User"),
+			wantError: errors.New("orm: 只支持结构体或者结构体的一级指针"),
 		},
 		{
 			name:      "slice",
 			entity:    []int{},
-			wantError: errors.New("orm: 只支持一级指针作为输入，例如 *User"),
+			wantError: errors.New("orm: 只支持结构体或者结构体的一级指针"),
 		},
 		{
 			name:      "basic type",
 			entity:    0,
-			wantError: errors.New("orm: 只支持一级指针作为输入，例如 *User"),
+			wantError: errors.New("orm: 只支持结构体或者结构体的一级指针"),
 		},
 
 		// 标签相关测试用例
@@ -309,39 +315,303 @@ func TestRegistry_get(t *testing.T) {
 			wantError: errs.NewErrInvalidTagContent("column"),
 		},
 		{
-			// 如果用户设置了一些奇奇怪怪的内容，这部分内容我们会忽略掉
-			name: "ignore tag",
+			name: "primary key tag",
 			entity: func() any {
-				// 我们把测试结构体定义在方法内部，防止被其它用例访问
-				type IgnoreTag struct {
-					FirstName string `orm:"abc=abc"`
+				type PrimaryKeyTag struct {
+					ID   uint64 `orm:"column=id,primary_key,auto_increment"`
+					Name string
 				}
-				return &IgnoreTag{}
+				return &PrimaryKeyTag{}
 			}(),
-			wantRes: &Model{
-				TableName: "ignore_tag",
-				FieldMap: map[string]*Field{
-					"FirstName": {
-						ColName: "first_name",
-						Type:    reflect.TypeOf(""),
-						GoName:  "FirstName",
-					},
-				},
-				ColumnMap: map[string]*Field{
-					"first_name": {
-						ColName: "first_name",
-						Type:    reflect.TypeOf(""),
-						GoName:  "FirstName",
-					},
-				},
-				Fields: []*Field{
-					{
-						ColName: "first_name",
-						Type:    reflect.TypeOf(""),
-						GoName:  "FirstName",
-					},
-				},
-			},
+			wantRes: func() *Model {
+				id := &Field{
+					ColName:       "id",
+					Type:          reflect.TypeOf(uint64(0)),
+					GoName:        "ID",
+					PrimaryKey:    true,
+					AutoIncrement: true,
+				}
+				name := &Field{
+					ColName: "name",
+					Type:    reflect.TypeOf(""),
+					GoName:  "Name",
+					Offset:  8,
+				}
+				return &Model{
+					TableName:     "primary_key_tag",
+					FieldMap:      map[string]*Field{"ID": id, "Name": name},
+					ColumnMap:     map[string]*Field{"id": id, "name": name},
+					Fields:        []*Field{id, name},
+					PrimaryKeys:   []*Field{id},
+					AutoIncrement: id,
+				}
+			}(),
+		},
+		{
+			name: "multiple auto increment",
+			entity: func() any {
+				type MultipleAutoIncrement struct {
+					ID  uint64 `orm:"auto_increment"`
+					Seq uint64 `orm:"auto_increment"`
+				}
+				return &MultipleAutoIncrement{}
+			}(),
+			wantError: errs.NewErrMultipleAutoIncrement("ID", "Seq"),
+		},
+		{
+			name: "rich tag",
+			entity: func() any {
+				type RichTag struct {
+					ID       uint64 `orm:"pk"`
+					Name     string `orm:"size=64,unique,index=idx_name_age"`
+					Age      int8   `orm:"default=18,index=idx_name_age"`
+					Remark   string `orm:"nullable,default='a=b'"`
+					Internal string `orm:"-"`
+				}
+				return &RichTag{}
+			}(),
+			wantRes: func() *Model {
+				id := &Field{
+					ColName:    "i_d",
+					Type:       reflect.TypeOf(uint64(0)),
+					GoName:     "ID",
+					PrimaryKey: true,
+				}
+				name := &Field{
+					ColName: "name",
+					Type:    reflect.TypeOf(""),
+					GoName:  "Name",
+					Offset:  8,
+					Size:    64,
+					Unique:  true,
+					Index:   "idx_name_age",
+				}
+				age := &Field{
+					ColName: "age",
+					Type:    reflect.TypeOf(int8(0)),
+					GoName:  "Age",
+					Offset:  24,
+					Default: "18",
+					Index:   "idx_name_age",
+				}
+				remark := &Field{
+					ColName:  "remark",
+					Type:     reflect.TypeOf(""),
+					GoName:   "Remark",
+					Offset:   32,
+					Nullable: true,
+					Default:  "'a=b'",
+				}
+				return &Model{
+					TableName:   "rich_tag",
+					FieldMap:    map[string]*Field{"ID": id, "Name": name, "Age": age, "Remark": remark},
+					ColumnMap:   map[string]*Field{"i_d": id, "name": name, "age": age, "remark": remark},
+					Fields:      []*Field{id, name, age, remark},
+					PrimaryKeys: []*Field{id},
+					Indexes:     map[string][]*Field{"idx_name_age": {name, age}},
+
+					IgnoredFields: map[string]struct{}{"Internal": {}},
+				}
+			}(),
+		},
+		{
+			// 未导出的字段不会被映射
+			name: "unexported field",
+			entity: func() any {
+				type UnexportedField struct {
+					Name  string
+					cache map[string]string
+				}
+				return &UnexportedField{}
+			}(),
+			wantRes: func() *Model {
+				name := &Field{
+					ColName: "name",
+					Type:    reflect.TypeOf(""),
+					GoName:  "Name",
+				}
+				return &Model{
+					TableName:     "unexported_field",
+					FieldMap:      map[string]*Field{"Name": name},
+					ColumnMap:     map[string]*Field{"name": name},
+					Fields:        []*Field{name},
+					IgnoredFields: map[string]struct{}{"cache": {}},
+				}
+			}(),
+		},
+		{
+			// 嵌入的结构体会被展开
+			name: "embedded",
+			entity: func() any {
+				type BaseModel struct {
+					Id         int64
+					CreateTime int64
+				}
+				type EmbeddedModel struct {
+					BaseModel
+					Name string
+				}
+				return &EmbeddedModel{}
+			}(),
+			wantRes: func() *Model {
+				id := &Field{
+					ColName:     "id",
+					Type:        reflect.TypeOf(int64(0)),
+					GoName:      "Id",
+					StructIndex: []int{0, 0},
+				}
+				createTime := &Field{
+					ColName:     "create_time",
+					Type:        reflect.TypeOf(int64(0)),
+					GoName:      "CreateTime",
+					Offset:      8,
+					StructIndex: []int{0, 1},
+				}
+				name := &Field{
+					ColName: "name",
+					Type:    reflect.TypeOf(""),
+					GoName:  "Name",
+					Offset:  16,
+				}
+				return &Model{
+					TableName: "embedded_model",
+					FieldMap:  map[string]*Field{"Id": id, "CreateTime": createTime, "Name": name},
+					ColumnMap: map[string]*Field{"id": id, "create_time": createTime, "name": name},
+					Fields:    []*Field{id, createTime, name},
+				}
+			}(),
+		},
+		{
+			// 嵌入的结构体指针，偏移量相对于指针指向的结构体
+			name: "embedded pointer",
+			entity: func() any {
+				type EmbeddedPtrModel struct {
+					Name string
+					*EmbeddedBase
+				}
+				return &EmbeddedPtrModel{}
+			}(),
+			wantRes: func() *Model {
+				pointers := []*EmbeddedPointer{{Offset: 16, Type: reflect.TypeOf(EmbeddedBase{})}}
+				name := &Field{
+					ColName: "name",
+					Type:    reflect.TypeOf(""),
+					GoName:  "Name",
+				}
+				id := &Field{
+					ColName:     "id",
+					Type:        reflect.TypeOf(int64(0)),
+					GoName:      "Id",
+					StructIndex: []int{1, 0},
+					Pointers:    pointers,
+				}
+				createTime := &Field{
+					ColName:     "create_time",
+					Type:        reflect.TypeOf(int64(0)),
+					GoName:      "CreateTime",
+					Offset:      8,
+					StructIndex: []int{1, 1},
+					Pointers:    pointers,
+				}
+				return &Model{
+					TableName: "embedded_ptr_model",
+					FieldMap:  map[string]*Field{"Id": id, "CreateTime": createTime, "Name": name},
+					ColumnMap: map[string]*Field{"id": id, "create_time": createTime, "name": name},
+					Fields:    []*Field{name, id, createTime},
+				}
+			}(),
+		},
+		{
+			name: "embedded field conflict",
+			entity: func() any {
+				type BaseModel struct {
+					Id int64
+				}
+				type FieldConflict struct {
+					BaseModel
+					Id int64
+				}
+				return &FieldConflict{}
+			}(),
+			wantError: errs.NewErrFieldConflict("Id", "id"),
+		},
+		{
+			name: "embedded column conflict",
+			entity: func() any {
+				type BaseModel struct {
+					CreateTime int64
+				}
+				type ColumnConflict struct {
+					BaseModel
+					Created int64 `orm:"column=create_time"`
+				}
+				return &ColumnConflict{}
+			}(),
+			wantError: errs.NewErrColumnConflict("create_time", "CreateTime", "Created"),
+		},
+		{
+			// 实现了 sql.Scanner 的结构体是一个列
+			name: "embedded scanner",
+			entity: func() any {
+				type EmbeddedScanner struct {
+					sql.NullString
+				}
+				return &EmbeddedScanner{}
+			}(),
+			wantRes: func() *Model {
+				str := &Field{
+					ColName: "null_string",
+					Type:    reflect.TypeOf(sql.NullString{}),
+					GoName:  "NullString",
+				}
+				return &Model{
+					TableName: "embedded_scanner",
+					FieldMap:  map[string]*Field{"NullString": str},
+					ColumnMap: map[string]*Field{"null_string": str},
+					Fields:    []*Field{str},
+				}
+			}(),
+		},
+		{
+			name: "invalid size",
+			entity: func() any {
+				type InvalidSize struct {
+					Name string `orm:"size=abc"`
+				}
+				return &InvalidSize{}
+			}(),
+			wantError: errs.NewErrInvalidTagContent("size=abc"),
+		},
+		{
+			name: "invalid flag",
+			entity: func() any {
+				type InvalidFlag struct {
+					Name string `orm:"size"`
+				}
+				return &InvalidFlag{}
+			}(),
+			wantError: errs.NewErrInvalidTagContent("size"),
+		},
+		{
+			// 不认识的 key 一般是拼写错误，需要返回错误
+			name: "unknown tag",
+			entity: func() any {
+				type UnknownTag struct {
+					FirstName string `orm:"colum=first"`
+				}
+				return &UnknownTag{}
+			}(),
+			wantError: errs.NewErrInvalidTagContent("colum=first"),
+		},
+		{
+			name: "unknown serializer tag",
+			entity: func() any {
+				type UnknownSerializerTag struct {
+					Tags []string `orm:"serialiser=json"`
+				}
+				return &UnknownSerializerTag{}
+			}(),
+			wantError: errs.NewErrInvalidTagContent("serialiser=json"),
 		},
 
 		{
@@ -444,6 +714,56 @@ func TestRegistry_get(t *testing.T) {
 	}
 }
 
+// 结构体和结构体指针共享同一份元数据
+func TestRegistry_Normalize(t *testing.T) {
+	r := NewRegistry()
+	byValue, err := r.Get(TestModel{})
+	require.NoError(t, err)
+	byPtr, err := r.Get(&TestModel{})
+	require.NoError(t, err)
+	assert.Same(t, byValue, byPtr)
+	assert.Equal(t, "test_model", byValue.TableName)
+
+	// 结构体上的 TableName 方法同样生效
+	m, err := r.Get(User01{})
+	require.NoError(t, err)
+	assert.Equal(t, "user_01_t", m.TableName)
+
+	// Register 之后 Get 拿到的是注册的元数据
+	registered, err := r.Register(TestModel{}, WithTableName("test_model_t"))
+	require.NoError(t, err)
+	m, err = r.Get(&TestModel{})
+	require.NoError(t, err)
+	assert.Same(t, registered, m)
+}
+
+func TestRegistry_RegisterAll(t *testing.T) {
+	type InvalidModel struct {
+		Name string `orm:"column"`
+	}
+	r := NewRegistry()
+	registered := r.MustRegister(&TestModel{}, WithTableName("test_model_t"))
+	require.NoError(t, r.RegisterAll(&User01{}, TestModel{}))
+	// 已经注册过的模型不会被覆盖
+	m, err := r.Get(&TestModel{})
+	require.NoError(t, err)
+	assert.Same(t, registered, m)
+
+	models := r.Models()
+	require.Len(t, models, 2)
+	assert.Equal(t, "test_model_t", models[0].TableName)
+	assert.Equal(t, "user_01_t", models[1].TableName)
+
+	err = r.RegisterAll(&User02{}, &InvalidModel{})
+	assert.Equal(t, errs.NewErrRegisterModel(reflect.TypeOf(&InvalidModel{}),
+		errs.NewErrInvalidTagContent("column")), err)
+	assert.ErrorIs(t, r.RegisterAll(1), errs.ErrPointOnly)
+
+	assert.Panics(t, func() {
+		r.MustRegister(&InvalidModel{})
+	})
+}
+
 type User01 struct {
 	FirstName string `orm:"column=first_name"`
 }
@@ -0,0 +1,239 @@
+// create by chencanhua in 2023/10/12
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"orm_framework/orm/internal/errs"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Serializer 字段和列之间的转换
+// 例如将结构体以 JSON 的形式存储，或者对列进行加密
+type Serializer interface {
+	// Serialize 将字段的值转换为写入数据库的值
+	Serialize(val any) (any, error)
+	// Deserialize 将数据库读取的 src 设置到字段上，dst 是可以 Set 的字段
+	Deserialize(src any, dst reflect.Value) error
+}
+
+// 内置的序列化方式，通过 orm:"serializer=json" 使用
+const (
+	SerializerJSON     = "json"
+	SerializerComma    = "comma"
+	SerializerUnixTime = "unixtime"
+)
+
+var builtinSerializers = map[string]Serializer{
+	SerializerJSON:     jsonSerializer{},
+	SerializerComma:    commaSerializer{},
+	SerializerUnixTime: unixTimeSerializer{},
+}
+
+// WithSerializer 注册序列化方式，标签中通过 name 使用，可以覆盖内置的序列化方式
+func WithSerializer(name string, s Serializer) RegistryOption {
+	return func(r *registry) {
+		if r.serializers == nil {
+			r.serializers = make(map[string]Serializer, 2)
+		}
+		r.serializers[name] = s
+	}
+}
+
+// WithTypeSerializer 为 Go 类型注册序列化方式，该类型的字段不需要标签
+// 标签指定的序列化方式优先级更高
+func WithTypeSerializer(typ reflect.Type, s Serializer) RegistryOption {
+	return func(r *registry) {
+		if r.typeSerializers == nil {
+			r.typeSerializers = make(map[reflect.Type]Serializer, 2)
+		}
+		r.typeSerializers[typ] = s
+	}
+}
+
+// ColumnValue 将字段的值转换为写入数据库的值，没有 Serializer 的时候原样返回
+func (f *Field) ColumnValue(val any) (any, error) {
+	if f.Serializer == nil {
+		return val, nil
+	}
+	return f.Serializer.Serialize(val)
+}
+
+// serializer 查找字段的序列化方式
+func (r *registry) serializer(name string, typ reflect.Type) (Serializer, error) {
+	if name == "" {
+		return r.typeSerializers[typ], nil
+	}
+	if s, ok := r.serializers[name]; ok {
+		return s, nil
+	}
+	if s, ok := builtinSerializers[name]; ok {
+		return s, nil
+	}
+	return nil, errs.NewErrUnknownSerializer(name)
+}
+
+// jsonSerializer 以 JSON 字符串的形式存储，nil 存储为 NULL
+type jsonSerializer struct{}
+
+func (j jsonSerializer) Serialize(val any) (any, error) {
+	if isNil(val) {
+		return nil, nil
+	}
+	res, err := json.Marshal(val)
+	if err != nil {
+		return nil, err
+	}
+	return string(res), nil
+}
+
+func (j jsonSerializer) Deserialize(src any, dst reflect.Value) error {
+	bs, ok, err := bytesOf(src)
+	if err != nil || !ok {
+		dst.Set(reflect.Zero(dst.Type()))
+		return err
+	}
+	ptr := reflect.New(dst.Type())
+	if err = json.Unmarshal(bs, ptr.Interface()); err != nil {
+		return err
+	}
+	dst.Set(ptr.Elem())
+	return nil
+}
+
+// commaSerializer 将切片以逗号分隔的形式存储，元素只支持字符串和数字
+// eg: []string{"a", "b"} => "a,b"
+type commaSerializer struct{}
+
+func (c commaSerializer) Serialize(val any) (any, error) {
+	v := reflect.ValueOf(val)
+	if v.Kind() != reflect.Slice {
+		return nil, errs.NewErrInvalidFieldValue(val, "slice")
+	}
+	items := make([]string, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		items = append(items, fmt.Sprint(v.Index(i).Interface()))
+	}
+	return strings.Join(items, ","), nil
+}
+
+func (c commaSerializer) Deserialize(src any, dst reflect.Value) error {
+	bs, ok, err := bytesOf(src)
+	if err != nil || !ok || len(bs) == 0 {
+		dst.Set(reflect.Zero(dst.Type()))
+		return err
+	}
+	if dst.Kind() != reflect.Slice {
+		return errs.NewErrInvalidFieldValue(src, dst.Type())
+	}
+	items := strings.Split(string(bs), ",")
+	res := reflect.MakeSlice(dst.Type(), len(items), len(items))
+	for i, item := range items {
+		if err = setString(res.Index(i), item); err != nil {
+			return err
+		}
+	}
+	dst.Set(res)
+	return nil
+}
+
+// unixTimeSerializer 将 time.Time 以秒级时间戳存储，零值存储为 0
+type unixTimeSerializer struct{}
+
+func (u unixTimeSerializer) Serialize(val any) (any, error) {
+	t, ok := val.(time.Time)
+	if !ok {
+		return nil, errs.NewErrInvalidFieldValue(val, "time.Time")
+	}
+	if t.IsZero() {
+		return int64(0), nil
+	}
+	return t.Unix(), nil
+}
+
+func (u unixTimeSerializer) Deserialize(src any, dst reflect.Value) error {
+	var sec int64
+	switch v := src.(type) {
+	case nil:
+	case int64:
+		sec = v
+	case []byte, string:
+		bs, _, _ := bytesOf(v)
+		val, err := strconv.ParseInt(string(bs), 10, 64)
+		if err != nil {
+			return err
+		}
+		sec = val
+	default:
+		return errs.NewErrInvalidFieldValue(src, dst.Type())
+	}
+	var t time.Time
+	if sec != 0 {
+		t = time.Unix(sec, 0)
+	}
+	val := reflect.ValueOf(t)
+	if !val.Type().AssignableTo(dst.Type()) {
+		return errs.NewErrInvalidFieldValue(src, dst.Type())
+	}
+	dst.Set(val)
+	return nil
+}
+
+// bytesOf 数据库读取的文本可能是 []byte 或者 string，为 nil 的时候 ok 为 false
+func bytesOf(src any) ([]byte, bool, error) {
+	switch v := src.(type) {
+	case nil:
+		return nil, false, nil
+	case []byte:
+		return v, true, nil
+	case string:
+		return []byte(v), true, nil
+	default:
+		return nil, false, errs.NewErrInvalidFieldValue(src, "[]byte")
+	}
+}
+
+// setString 将字符串转换为 dst 的类型
+func setString(dst reflect.Value, str string) error {
+	switch dst.Kind() {
+	case reflect.String:
+		dst.SetString(str)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		val, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			return err
+		}
+		dst.SetInt(val)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		val, err := strconv.ParseUint(str, 10, 64)
+		if err != nil {
+			return err
+		}
+		dst.SetUint(val)
+	case reflect.Float32, reflect.Float64:
+		val, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return err
+		}
+		dst.SetFloat(val)
+	default:
+		return errs.NewErrInvalidFieldValue(str, dst.Type())
+	}
+	return nil
+}
+
+func isNil(val any) bool {
+	if val == nil {
+		return true
+	}
+	v := reflect.ValueOf(val)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
@@ -0,0 +1,149 @@
+// create by chencanhua in 2023/10/12
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"orm_framework/orm/internal/errs"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSerializer(t *testing.T) {
+	type profile struct {
+		Nick string `json:"nick"`
+	}
+	testCases := []struct {
+		name       string
+		serializer Serializer
+		val        any
+		wantVal    any
+	}{
+		{
+			name:       "json",
+			serializer: jsonSerializer{},
+			val:        &profile{Nick: "Tom"},
+			wantVal:    `{"nick":"Tom"}`,
+		},
+		{
+			name:       "json map",
+			serializer: jsonSerializer{},
+			val:        map[string]int{"a": 1},
+			wantVal:    `{"a":1}`,
+		},
+		{
+			name:       "json nil",
+			serializer: jsonSerializer{},
+			val:        (*profile)(nil),
+			wantVal:    nil,
+		},
+		{
+			name:       "comma string",
+			serializer: commaSerializer{},
+			val:        []string{"a", "b", "c"},
+			wantVal:    "a,b,c",
+		},
+		{
+			name:       "comma int",
+			serializer: commaSerializer{},
+			val:        []int64{1, 2},
+			wantVal:    "1,2",
+		},
+		{
+			name:       "comma empty",
+			serializer: commaSerializer{},
+			val:        []string(nil),
+			wantVal:    "",
+		},
+		{
+			name:       "unix time",
+			serializer: unixTimeSerializer{},
+			val:        time.Unix(1696867200, 0),
+			wantVal:    int64(1696867200),
+		},
+		{
+			name:       "unix time zero",
+			serializer: unixTimeSerializer{},
+			val:        time.Time{},
+			wantVal:    int64(0),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := tc.serializer.Serialize(tc.val)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantVal, res)
+
+			// 反序列化之后应该得到原来的值
+			dst := reflect.New(reflect.TypeOf(tc.val)).Elem()
+			src := res
+			if str, ok := res.(string); ok {
+				src = []byte(str)
+			}
+			require.NoError(t, tc.serializer.Deserialize(src, dst))
+			assert.Equal(t, tc.val, dst.Interface())
+		})
+	}
+}
+
+func TestSerializer_Invalid(t *testing.T) {
+	_, err := commaSerializer{}.Serialize("a,b")
+	assert.Equal(t, errs.NewErrInvalidFieldValue("a,b", "slice"), err)
+
+	_, err = unixTimeSerializer{}.Serialize(int64(1))
+	assert.Equal(t, errs.NewErrInvalidFieldValue(int64(1), "time.Time"), err)
+
+	var ids []int
+	err = commaSerializer{}.Deserialize("1,x", reflect.ValueOf(&ids).Elem())
+	assert.Error(t, err)
+}
+
+type upperSerializer struct{}
+
+func (u upperSerializer) Serialize(val any) (any, error) {
+	return val.(string) + "!", nil
+}
+
+func (u upperSerializer) Deserialize(src any, dst reflect.Value) error {
+	dst.SetString(string(src.([]byte)))
+	return nil
+}
+
+func TestRegistry_Serializer(t *testing.T) {
+	type serializerUser struct {
+		Id      int64
+		Tags    []string          `orm:"serializer=comma"`
+		Extra   map[string]string `orm:"serializer=json"`
+		Birth   time.Time
+		Name    string    `orm:"serializer=upper"`
+		Created time.Time `orm:"serializer=json"`
+	}
+	r := NewRegistry(
+		WithSerializer("upper", upperSerializer{}),
+		WithTypeSerializer(reflect.TypeOf(time.Time{}), unixTimeSerializer{}))
+	m, err := r.Get(&serializerUser{})
+	require.NoError(t, err)
+	assert.Nil(t, m.FieldMap["Id"].Serializer)
+	assert.Equal(t, commaSerializer{}, m.FieldMap["Tags"].Serializer)
+	assert.Equal(t, jsonSerializer{}, m.FieldMap["Extra"].Serializer)
+	assert.Equal(t, upperSerializer{}, m.FieldMap["Name"].Serializer)
+	// 按类型注册
+	assert.Equal(t, unixTimeSerializer{}, m.FieldMap["Birth"].Serializer)
+	// 标签的优先级更高
+	assert.Equal(t, jsonSerializer{}, m.FieldMap["Created"].Serializer)
+
+	val, err := m.FieldMap["Name"].ColumnValue("Tom")
+	require.NoError(t, err)
+	assert.Equal(t, "Tom!", val)
+	val, err = m.FieldMap["Id"].ColumnValue(int64(1))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), val)
+
+	type unknownSerializer struct {
+		Name string `orm:"serializer=xml"`
+	}
+	_, err = r.Get(&unknownSerializer{})
+	assert.ErrorContains(t, err, errs.NewErrUnknownSerializer("xml").Error())
+}
@@ -0,0 +1,52 @@
+// create by chencanhua in 2023/9/20
+package orm
+
+const (
+	orderASC  = "ASC"
+	orderDESC = "DESC"
+)
+
+// OrderBy 排序，可以是列、聚合函数或者 SELECT 中的别名
+type OrderBy struct {
+	expr  Expression
+	order string
+}
+
+// Asc 按照字段升序，col 也可以是 SELECT 中定义的别名
+func Asc(col string) OrderBy {
+	return C(col).Asc()
+}
+
+// Desc 按照字段降序，col 也可以是 SELECT 中定义的别名
+func Desc(col string) OrderBy {
+	return C(col).Desc()
+}
+
+func (c Column) Asc() OrderBy {
+	return OrderBy{
+		expr:  c,
+		order: orderASC,
+	}
+}
+
+func (c Column) Desc() OrderBy {
+	return OrderBy{
+		expr:  c,
+		order: orderDESC,
+	}
+}
+
+// Asc 如果聚合函数设置了别名，那么排序时使用别名
+func (a Aggregate) Asc() OrderBy {
+	return OrderBy{
+		expr:  a,
+		order: orderASC,
+	}
+}
+
+func (a Aggregate) Desc() OrderBy {
+	return OrderBy{
+		expr:  a,
+		order: orderDESC,
+	}
+}
@@ -1,15 +1,32 @@
 // Package orm create by chencanhua in 2023/5/8
 package orm
 
+import "reflect"
+
 type op string
 
 const (
-	opEQ  = "="
-	opLT  = "<"
-	opGT  = ">"
-	opAND = "AND"
-	opOR  = "OR"
-	opNOT = "NOT"
+	opEQ        = "="
+	opNEQ       = "!="
+	opLT        = "<"
+	opLTEQ      = "<="
+	opGT        = ">"
+	opGTEQ      = ">="
+	opIN        = "IN"
+	opNOTIN     = "NOT IN"
+	opBETWEEN   = "BETWEEN"
+	opLIKE      = "LIKE"
+	opISNULL    = "IS NULL"
+	opISNOTNULL = "IS NOT NULL"
+	opEXISTS    = "EXISTS"
+	opNOTEXISTS = "NOT EXISTS"
+	opADD       = "+"
+	opSUB       = "-"
+	opMULTI     = "*"
+	opDIV       = "/"
+	opAND       = "AND"
+	opOR        = "OR"
+	opNOT       = "NOT"
 )
 
 /**
@@ -47,6 +64,97 @@ func (left Predicate) And(right Predicate) Predicate {
 	}
 }
 
+// binary 构建 left op arg 形式的 Predicate
+func binary(left Expression, o op, arg any) Predicate {
+	return Predicate{
+		left:  left,
+		op:    o,
+		right: exprOf(arg),
+	}
+}
+
+// in 构建 IN 和 NOT IN
+// 切片会展开成多个占位符，例如 In([]int{1, 2}, 3) 等价于 In(1, 2, 3)，也可以只传入一个子查询
+// 没有任何值的时候，IN 恒为假，NOT IN 恒为真
+func in(left Expression, o op, vals []any) Predicate {
+	if len(vals) == 1 {
+		// 子查询自带括号
+		if sub, ok := vals[0].(Subquery); ok {
+			return Predicate{
+				left:  left,
+				op:    o,
+				right: sub,
+			}
+		}
+	}
+	vals = flatten(vals)
+	if len(vals) == 0 {
+		if o == opNOTIN {
+			return Raw("1 = 1").AsPredicate()
+		}
+		return Raw("1 = 0").AsPredicate()
+	}
+	return Predicate{
+		left:  left,
+		op:    o,
+		right: values{vals: vals},
+	}
+}
+
+// flatten 展开 vals 中的切片和数组，[]byte 除外
+func flatten(vals []any) []any {
+	res := make([]any, 0, len(vals))
+	for _, val := range vals {
+		if _, ok := val.([]byte); ok {
+			res = append(res, val)
+			continue
+		}
+		rv := reflect.ValueOf(val)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			res = append(res, val)
+			continue
+		}
+		for i := 0; i < rv.Len(); i++ {
+			res = append(res, rv.Index(i).Interface())
+		}
+	}
+	return res
+}
+
+func between(left Expression, start any, end any) Predicate {
+	return Predicate{
+		left: left,
+		op:   opBETWEEN,
+		right: betweenRange{
+			start: exprOf(start),
+			end:   exprOf(end),
+		},
+	}
+}
+
+// unary 构建 IS NULL 这种只有左边的 Predicate
+func unary(left Expression, o op) Predicate {
+	return Predicate{
+		left: left,
+		op:   o,
+	}
+}
+
+// values 代表 IN 后面的值列表
+type values struct {
+	vals []any
+}
+
+func (values) expr() {}
+
+// betweenRange 代表 BETWEEN 后面的 start AND end
+type betweenRange struct {
+	start Expression
+	end   Expression
+}
+
+func (betweenRange) expr() {}
+
 // Expression 是一个标记接口，代表表达式
 type Expression interface {
 	expr()
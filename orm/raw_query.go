@@ -0,0 +1,121 @@
+// create by chencanhua in 2023/9/24
+package orm
+
+import (
+	"context"
+	"database/sql"
+)
+
+var (
+	_ QueryBuilder = &RawQuerier[any]{}
+	_ Querier[any] = &RawQuerier[any]{}
+	_ Executor     = &RawQuerier[any]{}
+)
+
+// RawQuerier 原生查询
+// 结果集按照列名映射到 T，T 可以是任意结构体，不需要和表对应
+type RawQuerier[T any] struct {
+	core
+	sess Session
+	sql  string
+	args []any
+}
+
+// RawQuery 构建原生查询
+// eg: RawQuery[UserDTO](db, "SELECT `id`,`name` FROM `user` WHERE `id` = ?", 1)
+func RawQuery[T any](sess Session, query string, args ...any) *RawQuerier[T] {
+	return &RawQuerier[T]{
+		core: sess.getCore(),
+		sess: sess,
+		sql:  query,
+		args: args,
+	}
+}
+
+// Mapping 设置当前查询结果集的映射模式
+func (r *RawQuerier[T]) Mapping(mode MappingMode) *RawQuerier[T] {
+	r.mapping = mode
+	return r
+}
+
+func (r *RawQuerier[T]) Build() (*Query, error) {
+	return &Query{
+		SQL:  r.sql,
+		Args: r.args,
+	}, nil
+}
+
+func (r *RawQuerier[T]) Get(ctx context.Context) (*T, error) {
+	qc := &QueryContext{
+		Type:    "RAW",
+		Builder: r,
+	}
+	res := get[T](ctx, r.sess, r.core, qc)
+	if res.Result != nil {
+		return res.Result.(*T), nil
+	}
+	return nil, res.Err
+}
+
+func (r *RawQuerier[T]) GetMulti(ctx context.Context) ([]*T, error) {
+	qc := &QueryContext{
+		Type:    "RAW",
+		Builder: r,
+	}
+	res := getMulti[T](ctx, r.sess, r.core, qc)
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	if ts, ok := res.Result.([]*T); ok {
+		return ts, nil
+	}
+	return []*T{}, nil
+}
+
+func (r *RawQuerier[T]) Exec(ctx context.Context) sql.Result {
+	qc := &QueryContext{
+		Type:    "RAW",
+		Builder: r,
+	}
+	result := exec(ctx, r.sess, r.core, qc)
+	if result.Result != nil {
+		return &Result{
+			res: result.Result.(sql.Result),
+			err: nil,
+		}
+	}
+	return &Result{
+		err: result.Err,
+	}
+}
+
+// GetAs 将 Selector 的结果映射到 R，而不是 T
+// 用于 JOIN 或者聚合函数这种结果集和 T 对不上的场景
+// eg: GetAs[OrderDTO](ctx, NewSelector[Order](db).From(join))
+func GetAs[R any, T any](ctx context.Context, s *Selector[T]) (*R, error) {
+	qc := &QueryContext{
+		Type:    "SELECT",
+		Builder: s,
+	}
+	res := get[R](ctx, s.sess, s.core, qc)
+	if res.Result != nil {
+		return res.Result.(*R), nil
+	}
+	return nil, res.Err
+}
+
+// GetMultiAs 同 GetAs，返回全部结果
+func GetMultiAs[R any, T any](ctx context.Context, s *Selector[T]) ([]*R, error) {
+	qc := &QueryContext{
+		Type:    "SELECT",
+		Builder: s,
+	}
+	res := getMulti[R](ctx, s.sess, s.core, qc)
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	if rs, ok := res.Result.([]*R); ok {
+		return rs, nil
+	}
+	return []*R{}, nil
+}
@@ -0,0 +1,165 @@
+// create by chencanhua in 2023/9/24
+package orm
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"orm_framework/orm/internal/errs"
+	"testing"
+)
+
+func TestRawQuerier_Get(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	db, err := OpenDB(mockDB)
+	require.NoError(t, err)
+
+	type UserDTO struct {
+		Id   int
+		Name string `orm:"column=first_name"`
+	}
+
+	testCases := []struct {
+		name    string
+		mock    func()
+		q       *RawQuerier[UserDTO]
+		wantRes *UserDTO
+		wantErr error
+	}{
+		{
+			name: "query error",
+			mock: func() {
+				mock.ExpectQuery("SELECT .*").WillReturnError(errors.New("query error"))
+			},
+			q:       RawQuery[UserDTO](db, "SELECT * FROM `test_model`"),
+			wantErr: errors.New("query error"),
+		},
+		{
+			name: "no rows",
+			mock: func() {
+				mock.ExpectQuery("SELECT .*").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "first_name"}))
+			},
+			q:       RawQuery[UserDTO](db, "SELECT * FROM `test_model`"),
+			wantErr: ErrNoRows,
+		},
+		{
+			// 按照标签上的列名映射
+			name: "tag column",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "first_name"})
+				rows.AddRow([]byte("1"), []byte("Deng"))
+				mock.ExpectQuery("SELECT `id`,`first_name` FROM `test_model` WHERE `id` = ?").
+					WithArgs(1).WillReturnRows(rows)
+			},
+			q:       RawQuery[UserDTO](db, "SELECT `id`,`first_name` FROM `test_model` WHERE `id` = ?", 1),
+			wantRes: &UserDTO{Id: 1, Name: "Deng"},
+		},
+		{
+			name: "unknown column",
+			mock: func() {
+				rows := sqlmock.NewRows([]string{"id", "age"})
+				rows.AddRow([]byte("1"), []byte("18"))
+				mock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+			},
+			q:       RawQuery[UserDTO](db, "SELECT `id`,`age` FROM `test_model`"),
+			wantErr: errs.NewErrUnknownColumn("age"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock()
+			res, err := tc.q.Get(context.Background())
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantRes, res)
+		})
+	}
+}
+
+func TestRawQuerier_Exec(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	db, err := OpenDB(mockDB)
+	require.NoError(t, err)
+
+	mock.ExpectExec("TRUNCATE TABLE `test_model`").WillReturnResult(driver.RowsAffected(0))
+	res := RawQuery[TestModel](db, "TRUNCATE TABLE `test_model`").Exec(context.Background())
+	affected, err := res.RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), affected)
+}
+
+func TestGetAs(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	db, err := OpenDB(mockDB)
+	require.NoError(t, err)
+
+	type Order struct {
+		Id     int
+		UserId int
+	}
+	type OrderDetail struct {
+		OrderId int
+		ItemId  int
+	}
+	// 用于接收 JOIN 的结果
+	type OrderItem struct {
+		Id     int
+		ItemId int
+	}
+	// 用于接收聚合函数的结果
+	type AgeStat struct {
+		FirstName string
+		AvgAge    float64
+	}
+
+	t.Run("join", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "item_id"})
+		rows.AddRow([]byte("1"), []byte("10"))
+		rows.AddRow([]byte("1"), []byte("11"))
+		mock.ExpectQuery("SELECT `t1`.`id`,`t2`.`item_id` FROM .*").WillReturnRows(rows)
+
+		t1 := TableOf(&Order{}).As("t1")
+		t2 := TableOf(&OrderDetail{}).As("t2")
+		s := NewSelector[Order](db).Select(t1.C("Id"), t2.C("ItemId")).
+			From(t1.Join(t2).On(t1.C("Id").Eq(t2.C("OrderId"))))
+		res, err := GetMultiAs[OrderItem](context.Background(), s)
+		require.NoError(t, err)
+		assert.Equal(t, []*OrderItem{{Id: 1, ItemId: 10}, {Id: 1, ItemId: 11}}, res)
+	})
+
+	t.Run("aggregate", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"first_name", "avg_age"})
+		rows.AddRow([]byte("Deng"), []byte("18.5"))
+		mock.ExpectQuery("SELECT `first_name`,AVG\\(`age`\\) AS `avg_age` FROM .*").WillReturnRows(rows)
+
+		s := NewSelector[TestModel](db).
+			Select(C("FirstName"), Avg("Age").As("avg_age")).GroupBy(C("FirstName"))
+		res, err := GetAs[AgeStat](context.Background(), s)
+		require.NoError(t, err)
+		assert.Equal(t, &AgeStat{FirstName: "Deng", AvgAge: 18.5}, res)
+	})
+
+	t.Run("no rows", func(t *testing.T) {
+		mock.ExpectQuery("SELECT .*").
+			WillReturnRows(sqlmock.NewRows([]string{"first_name", "avg_age"}))
+		s := NewSelector[TestModel](db).Select(C("FirstName"), Avg("Age").As("avg_age"))
+		_, err := GetAs[AgeStat](context.Background(), s)
+		assert.Equal(t, ErrNoRows, err)
+
+		mock.ExpectQuery("SELECT .*").
+			WillReturnRows(sqlmock.NewRows([]string{"first_name", "avg_age"}))
+		res, err := GetMultiAs[AgeStat](context.Background(), s)
+		require.NoError(t, err)
+		assert.Equal(t, []*AgeStat{}, res)
+	})
+}
@@ -1,7 +1,11 @@
 // create by chencanhua in 2023/6/24
 package orm
 
-import "database/sql"
+import (
+	"database/sql"
+	"orm_framework/orm/internal/errs"
+	"reflect"
+)
 
 type Result struct {
 	err error
@@ -21,3 +25,36 @@ func (r *Result) RowsAffected() (int64, error) {
 	}
 	return r.res.RowsAffected()
 }
+
+// returningResult 通过 RETURNING 执行的插入
+type returningResult struct {
+	// rows 返回的行数
+	rows int64
+	// id 最后一行回填的自增列，hasId 为 false 的时候没有返回自增列
+	id    int64
+	hasId bool
+}
+
+func (r returningResult) LastInsertId() (int64, error) {
+	if !r.hasId {
+		return 0, errs.ErrNoLastInsertId
+	}
+	return r.id, nil
+}
+
+func (r returningResult) RowsAffected() (int64, error) {
+	return r.rows, nil
+}
+
+// toInt64 将整数类型的自增列转换为 int64
+func toInt64(val any) (int64, bool) {
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint()), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,150 @@
+// create by chencanhua in 2023/9/25
+package orm
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Queryable 可以直接发起查询的语句，例如 Selector 和 RawQuerier
+// 用于 GetScalar 这类不依赖模型的查询
+type Queryable interface {
+	QueryBuilder
+	session() Session
+	queryType() string
+}
+
+var (
+	_ Queryable = &Selector[any]{}
+	_ Queryable = &RawQuerier[any]{}
+)
+
+// GetScalar 返回第一行第一列，例如 SELECT COUNT(*)
+// 没有数据时返回 ErrNoRows
+// eg: GetScalar[int64](ctx, NewSelector[User](db).Select(Count("Id")))
+func GetScalar[V any](ctx context.Context, q Queryable) (V, error) {
+	res := runQuery(ctx, q, func(rows *sql.Rows) (any, error) {
+		if !rows.Next() {
+			if err := rows.Err(); err != nil {
+				return nil, err
+			}
+			return nil, ErrNoRows
+		}
+		var v V
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	})
+	if res.Err != nil {
+		var v V
+		return v, res.Err
+	}
+	v, _ := res.Result.(V)
+	return v, nil
+}
+
+// GetMap 返回第一行，key 为列名，value 为驱动返回的原始值
+// 没有数据时返回 ErrNoRows
+func GetMap(ctx context.Context, q Queryable) (map[string]any, error) {
+	res := runQuery(ctx, q, func(rows *sql.Rows) (any, error) {
+		if !rows.Next() {
+			if err := rows.Err(); err != nil {
+				return nil, err
+			}
+			return nil, ErrNoRows
+		}
+		return scanMap(rows)
+	})
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	m, _ := res.Result.(map[string]any)
+	return m, nil
+}
+
+// GetMaps 返回全部数据，没有数据时返回空切片
+func GetMaps(ctx context.Context, q Queryable) ([]map[string]any, error) {
+	res := runQuery(ctx, q, func(rows *sql.Rows) (any, error) {
+		ms := make([]map[string]any, 0, 8)
+		for rows.Next() {
+			m, err := scanMap(rows)
+			if err != nil {
+				return nil, err
+			}
+			ms = append(ms, m)
+		}
+		return ms, nil
+	})
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	if ms, ok := res.Result.([]map[string]any); ok {
+		return ms, nil
+	}
+	return []map[string]any{}, nil
+}
+
+func runQuery(ctx context.Context, q Queryable, scan func(rows *sql.Rows) (any, error)) *QueryResult {
+	sess := q.session()
+	qc := &QueryContext{
+		Type:    q.queryType(),
+		Builder: q,
+	}
+	return query(ctx, sess, sess.getCore(), qc, scan)
+}
+
+func scanMap(rows *sql.Rows) (map[string]any, error) {
+	cs, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	vals := make([]any, len(cs))
+	for i := range vals {
+		vals[i] = new(any)
+	}
+	if err = rows.Scan(vals...); err != nil {
+		return nil, err
+	}
+	m := make(map[string]any, len(cs))
+	for i, c := range cs {
+		m[c] = *(vals[i].(*any))
+	}
+	return m, nil
+}
+
+func (s *Selector[T]) session() Session {
+	return s.sess
+}
+
+func (s *Selector[T]) queryType() string {
+	return "SELECT"
+}
+
+// GetMap 同 orm.GetMap
+func (s *Selector[T]) GetMap(ctx context.Context) (map[string]any, error) {
+	return GetMap(ctx, s)
+}
+
+// GetMaps 同 orm.GetMaps
+func (s *Selector[T]) GetMaps(ctx context.Context) ([]map[string]any, error) {
+	return GetMaps(ctx, s)
+}
+
+func (r *RawQuerier[T]) session() Session {
+	return r.sess
+}
+
+func (r *RawQuerier[T]) queryType() string {
+	return "RAW"
+}
+
+// GetMap 同 orm.GetMap
+func (r *RawQuerier[T]) GetMap(ctx context.Context) (map[string]any, error) {
+	return GetMap(ctx, r)
+}
+
+// GetMaps 同 orm.GetMaps
+func (r *RawQuerier[T]) GetMaps(ctx context.Context) ([]map[string]any, error) {
+	return GetMaps(ctx, r)
+}
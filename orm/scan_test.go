@@ -0,0 +1,109 @@
+// create by chencanhua in 2023/9/25
+package orm
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestGetScalar(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	// 确认经过了 middleware
+	var queries []string
+	mdl := func(next Handler) Handler {
+		return func(ctx context.Context, qc *QueryContext) *QueryResult {
+			q, err := qc.Builder.Build()
+			if err == nil {
+				queries = append(queries, qc.Type+" "+q.SQL)
+			}
+			return next(ctx, qc)
+		}
+	}
+	db, err := OpenDB(mockDB, WithMiddleWare(mdl))
+	require.NoError(t, err)
+
+	t.Run("count", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"COUNT(`id`)"})
+		rows.AddRow([]byte("12"))
+		mock.ExpectQuery("SELECT COUNT\\(`id`\\) FROM `test_model` WHERE `age` > ?").
+			WithArgs(18).WillReturnRows(rows)
+		res, err := GetScalar[int64](context.Background(),
+			NewSelector[TestModel](db).Select(Count("Id")).Where(C("Age").GT(18)))
+		require.NoError(t, err)
+		assert.Equal(t, int64(12), res)
+		assert.Equal(t, []string{"SELECT SELECT COUNT(`id`) FROM `test_model` WHERE `age` > ?;"}, queries)
+	})
+
+	t.Run("raw", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"name"})
+		rows.AddRow([]byte("Deng"))
+		mock.ExpectQuery("SELECT `first_name` FROM `test_model` LIMIT 1").WillReturnRows(rows)
+		res, err := GetScalar[string](context.Background(),
+			RawQuery[TestModel](db, "SELECT `first_name` FROM `test_model` LIMIT 1"))
+		require.NoError(t, err)
+		assert.Equal(t, "Deng", res)
+	})
+
+	t.Run("no rows", func(t *testing.T) {
+		mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"cnt"}))
+		_, err := GetScalar[int64](context.Background(), NewSelector[TestModel](db).Select(Count("Id")))
+		assert.Equal(t, ErrNoRows, err)
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		mock.ExpectQuery("SELECT .*").WillReturnError(errors.New("query error"))
+		_, err := GetScalar[int64](context.Background(), NewSelector[TestModel](db).Select(Count("Id")))
+		assert.Equal(t, errors.New("query error"), err)
+	})
+}
+
+func TestSelector_GetMap(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	db, err := OpenDB(mockDB)
+	require.NoError(t, err)
+
+	t.Run("get map", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"first_name", "cnt"})
+		rows.AddRow("Deng", int64(3))
+		mock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+		res, err := NewSelector[TestModel](db).
+			Select(C("FirstName"), Count("Id").As("cnt")).GroupBy(C("FirstName")).
+			GetMap(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"first_name": "Deng", "cnt": int64(3)}, res)
+	})
+
+	t.Run("get map no rows", func(t *testing.T) {
+		mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"first_name"}))
+		_, err := NewSelector[TestModel](db).GetMap(context.Background())
+		assert.Equal(t, ErrNoRows, err)
+	})
+
+	t.Run("get maps", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"first_name", "cnt"})
+		rows.AddRow("Deng", int64(3))
+		rows.AddRow("Da", int64(1))
+		mock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+		res, err := RawQuery[TestModel](db, "SELECT `first_name`, COUNT(*) AS `cnt` FROM `test_model`").
+			GetMaps(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []map[string]any{
+			{"first_name": "Deng", "cnt": int64(3)},
+			{"first_name": "Da", "cnt": int64(1)},
+		}, res)
+	})
+
+	t.Run("get maps no rows", func(t *testing.T) {
+		mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"first_name"}))
+		res, err := NewSelector[TestModel](db).GetMaps(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []map[string]any{}, res)
+	})
+}
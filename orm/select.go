@@ -7,6 +7,11 @@ import (
 	"orm_framework/orm/internal/errs"
 )
 
+var (
+	_ QueryBuilder = &Selector[any]{}
+	_ Querier[any] = &Selector[any]{}
+)
+
 type Selectable interface {
 	selectable()
 }
@@ -26,40 +31,74 @@ func NewSelector[T any](sess Session) *Selector[T] {
 		sess: sess,
 		selectorBuilder: selectorBuilder{
 			builder: builder{
-				core:   c,
-				quoter: c.dialect.quoter(),
-				buffer: bytebufferpool.Get(),
+				core: c,
 			},
 		},
 	}
 }
 
+// AsSubquery 将当前查询作为子查询
+func (s *Selector[T]) AsSubquery(alias string) Subquery {
+	table := s.table
+	if table == nil {
+		table = TableOf(new(T))
+	}
+	return Subquery{
+		s:       s,
+		columns: s.columns,
+		from:    table,
+		alias:   alias,
+	}
+}
+
 func (s *Selector[T]) Build() (*Query, error) {
 	// 使用完毕之后放回
+	s.reset()
 	defer bytebufferpool.Put(s.buffer)
+	if err := s.build(); err != nil {
+		return nil, err
+	}
+	return s.end(), nil
+}
+
+// buildRaw 构建不带分号，也没有改写占位符的查询，用于子查询
+// 占位符需要等外层查询构建完毕之后统一改写
+func (s *Selector[T]) buildRaw() (*Query, error) {
+	s.reset()
+	defer bytebufferpool.Put(s.buffer)
+	if err := s.build(); err != nil {
+		return nil, err
+	}
+	return &Query{
+		SQL:  s.buffer.String(),
+		Args: s.args,
+	}, nil
+}
+
+func (s *Selector[T]) build() error {
 	m, err := s.r.Get(new(T))
 	if err != nil {
-		return nil, err
+		return err
 	}
 	s.model = m
 	s.writeString("SELECT ")
 	// 构建select内容
 	err = s.buildColumns()
 	if err != nil {
-		return nil, err
+		return err
 	}
 	s.writeString(" FROM ")
 
 	// 构建table内容，这里进行支持相关join关联
 	if err = s.buildTable(s.table); err != nil {
-		return nil, err
+		return err
 	}
 
 	if len(s.where) > 0 {
 		// 类似这种可有可无的部分，都要在前面加一个空格
 		s.writeString(" WHERE ")
 		if err = s.buildPredicates(s.where); err != nil {
-			return nil, err
+			return err
 		}
 	}
 
@@ -70,7 +109,7 @@ func (s *Selector[T]) Build() (*Query, error) {
 				s.writeByte(',')
 			}
 			if err = s.buildColumn(&Column{column: c.column}); err != nil {
-				return nil, err
+				return err
 			}
 		}
 	}
@@ -79,25 +118,29 @@ func (s *Selector[T]) Build() (*Query, error) {
 		s.writeString(" HAVING ")
 		// HAVING 是可以用别名的
 		if err = s.buildPredicates(s.having); err != nil {
-			return nil, err
+			return err
 		}
 	}
 
-	if s.limit > 0 {
-		s.writeString(" LIMIT ?")
-		s.addArgs(s.limit)
+	if len(s.orderBy) > 0 {
+		s.writeString(" ORDER BY ")
+		for i, ob := range s.orderBy {
+			if i > 0 {
+				s.writeByte(',')
+			}
+			if err = s.buildOrderBy(ob); err != nil {
+				return err
+			}
+		}
 	}
 
-	if s.offset > 0 {
-		s.writeString(" OFFSET ?")
-		s.addArgs(s.offset)
+	if s.limit > 0 || s.offset > 0 {
+		if err = s.dialect.BuildLimitOffset(&s.selectorBuilder, s.limit, s.offset); err != nil {
+			return err
+		}
 	}
 
-	s.writeByte(';')
-	return &Query{
-		SQL:  s.buffer.String(),
-		Args: s.args,
-	}, nil
+	return nil
 }
 
 func (s *Selector[T]) buildTable(table TableReference) error {
@@ -145,95 +188,75 @@ func (s *Selector[T]) buildTable(table TableReference) error {
 			s.writeByte(')')
 		} else if len(t.on) > 0 {
 			s.writeString(" ON ")
-			p := t.on[0]
-			for i := 1; i < len(t.on); i++ {
-				p = p.And(t.on[i])
-			}
-			if err = s.buildExpression(p); err != nil {
+			if err = s.buildPredicates(t.on); err != nil {
 				return err
 			}
 		}
 		s.writeByte(')')
+	case Subquery:
+		if err := s.buildSubquery(t); err != nil {
+			return err
+		}
+		s.buildAs(t.alias)
 	default:
 		return errs.NewErrUnsupportedTable(table)
 	}
 	return nil
 }
 
-// buildExpression 构建Where后面部分
-// 这里case都实现了expr方法
-func (s *Selector[T]) buildExpression(expression Expression) error {
-	if expression == nil {
-		return nil
-	}
-	switch expr := expression.(type) {
+// buildOrderBy 构建单个排序项
+// 字段优先按照模型解析，解析不到的时候再尝试 SELECT 中的别名
+func (s *Selector[T]) buildOrderBy(ob OrderBy) error {
+	switch expr := ob.expr.(type) {
 	case Column:
-		return s.buildColumn(&expr)
-	case Aggregate:
-		return s.buildAggregate(expr, false)
-	case Value:
-		s.writeByte('?')
-		s.addArgs(expr.val)
-	case RawExpr:
-		s.writeString(expr.raw)
-		s.addArgs(expr.args...)
-	case Predicate:
-		_, lp := expr.left.(Predicate)
-		if lp {
-			s.writeByte('(')
+		if _, ok := s.model.FieldMap[expr.column]; !ok && expr.table == nil && s.hasAlias(expr.column) {
+			s.quote(expr.column)
+			break
 		}
-		if err := s.buildExpression(expr.left); err != nil {
+		if err := s.buildColumn(&expr); err != nil {
 			return err
 		}
-		if lp {
-			s.writeByte(')')
-		}
-
-		// 可能只有左边
-		if expr.op == "" {
-			return nil
-		}
-
-		s.writeByte(' ')
-		s.writeString(string(expr.op))
-		s.writeByte(' ')
-		_, lp = expr.right.(Predicate)
-		if lp {
-			s.writeByte('(')
+	case Aggregate:
+		if expr.alias != "" {
+			s.quote(expr.alias)
+			break
 		}
-		if err := s.buildExpression(expr.right); err != nil {
+		if err := s.buildAggregate(expr, false); err != nil {
 			return err
 		}
-		if lp {
-			s.writeByte(')')
+	default:
+		if err := s.buildExpression(expr); err != nil {
+			return err
 		}
 	}
+	s.writeByte(' ')
+	s.writeString(ob.order)
 	return nil
 }
 
-func (s *Selector[T]) buildPredicates(ps []Predicate) error {
-	p := ps[0]
-	for i := 1; i < len(ps); i++ {
-		p = p.And(ps[i])
-	}
-	return s.buildExpression(p)
-}
-
-func (s *Selector[T]) buildAggregate(a Aggregate, useAlias bool) error {
-	s.writeString(a.fn)
-	s.writeByte('(')
-	if err := s.buildColumn(&Column{column: a.arg}); err != nil {
-		return err
-	}
-	s.writeByte(')')
-	if useAlias {
-		s.buildAs(a.alias)
+// hasAlias SELECT 中是否定义了该别名
+func (s *Selector[T]) hasAlias(alias string) bool {
+	for _, c := range s.columns {
+		switch val := c.(type) {
+		case Column:
+			if val.alias == alias {
+				return true
+			}
+		case Aggregate:
+			if val.alias == alias {
+				return true
+			}
+		case MathExpr:
+			if val.alias == alias {
+				return true
+			}
+		}
 	}
-	return nil
+	return false
 }
 
 // buildColumns 构建select后面部分
-// 这里的case都有实现了selectable接口
+// 这里的元素都有实现了selectable接口
 func (s *Selector[T]) buildColumns() error {
 	if len(s.columns) == 0 {
 		s.writeByte('*')
@@ -243,41 +266,13 @@ func (s *Selector[T]) buildColumns() error {
 		if i > 0 {
 			s.writeByte(',')
 		}
-		switch val := c.(type) {
-		case Column:
-			err := s.buildColumn(&Column{column: val.column})
-			if err != nil {
-				return err
-			}
-			s.buildAs(val.alias)
-		case Aggregate:
-			return s.buildAggregate(val, true)
-		case RawExpr:
-			s.writeString(val.raw)
-			s.addArgs(val.args...)
+		if err := s.buildSelectable(c); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
-// buildAs 构建as
-func (s *Selector[T]) buildAs(alias string) {
-	if alias != "" {
-		s.writeString(" AS ")
-		s.quote(alias)
-	}
-}
-
-func (s *Selector[T]) addArgs(args ...any) {
-	if len(args) == 0 {
-		return
-	}
-	if s.args == nil {
-		s.args = make([]any, 0, 8)
-	}
-	s.args = append(s.args, args...)
-}
-
 func (s *Selector[T]) Select(cols ...Selectable) *Selector[T] {
 	s.columns = cols
 	return s
@@ -299,6 +294,12 @@ func (s *Selector[T]) GroupBy(cols ...Column) *Selector[T] {
 	return s
 }
 
+// OrderBy 设置 order by 子句，例如 OrderBy(Asc("Id"), Desc("Age"))
+func (s *Selector[T]) OrderBy(orderBys ...OrderBy) *Selector[T] {
+	s.orderBy = orderBys
+	return s
+}
+
 func (s *Selector[T]) Having(ps ...Predicate) *Selector[T] {
 	s.having = ps
 	return s
@@ -314,6 +315,12 @@ func (s *Selector[T]) Limit(limit int) *Selector[T] {
 	return s
 }
 
+// Mapping 设置当前查询结果集的映射模式
+func (s *Selector[T]) Mapping(mode MappingMode) *Selector[T] {
+	s.mapping = mode
+	return s
+}
+
 func (s *Selector[T]) Get(ctx context.Context) (*T, error) {
 	qc := &QueryContext{
 		Type:    "SELECT",
@@ -326,6 +333,19 @@ func (s *Selector[T]) Get(ctx context.Context) (*T, error) {
 	return nil, res.Err
 }
 
-func (s *Selector[T]) GetMulti(ctx context.Context) (*[]T, error) {
-	panic("implement me")
+// GetMulti 返回全部结果
+// 没有数据时返回空切片和 nil，不会返回 ErrNoRows
+func (s *Selector[T]) GetMulti(ctx context.Context) ([]*T, error) {
+	qc := &QueryContext{
+		Type:    "SELECT",
+		Builder: s,
+	}
+	res := getMulti[T](ctx, s.sess, s.core, qc)
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	if ts, ok := res.Result.([]*T); ok {
+		return ts, nil
+	}
+	return []*T{}, nil
 }
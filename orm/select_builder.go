@@ -6,6 +6,7 @@ type selectorBuilderAttribute struct {
 	having  []Predicate
 	columns []Selectable
 	groupBy []Column
+	orderBy []OrderBy
 	offset  int
 	limit   int
 }
@@ -14,3 +15,9 @@ type selectorBuilder struct {
 	builder
 	selectorBuilderAttribute
 }
+
+var _ SelectBuilder = &selectorBuilder{}
+
+func (s *selectorBuilder) HasOrderBy() bool {
+	return len(s.orderBy) > 0
+}
@@ -11,12 +11,19 @@ import (
 	"github.com/stretchr/testify/require"
 	"orm_framework/orm/internal/errs"
 	"orm_framework/orm/internal/valuer"
+	"orm_framework/orm/model"
 	"testing"
+	"time"
 )
 
 func TestSelector_Build(t *testing.T) {
 	d := mysqlDB()
 	db, _ := OpenDB(d)
+	type SerializerModel struct {
+		Id      int64
+		Tags    []string  `orm:"serializer=comma"`
+		Created time.Time `orm:"serializer=unixtime"`
+	}
 	testCases := []struct {
 		name    string
 		builder QueryBuilder
@@ -223,6 +230,253 @@ func TestSelector_Build(t *testing.T) {
 			builder: NewSelector[TestModel](db).GroupBy(C("Invalid")),
 			wantErr: errs.NewErrUnknownField("Invalid"),
 		},
+		{
+			name: "compare operators",
+			builder: NewSelector[TestModel](db).Where(
+				C("Id").NotEq(1), C("Age").LTEq(30), C("Age").GTEq(18)),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE ((`id` != ?) AND (`age` <= ?)) AND (`age` >= ?);",
+				Args: []any{1, 30, 18},
+			},
+		},
+		{
+			name:    "in",
+			builder: NewSelector[TestModel](db).Where(C("Id").In(1, 2, 3)),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE `id` IN (?,?,?);",
+				Args: []any{1, 2, 3},
+			},
+		},
+		{
+			// 切片会被展开
+			name:    "in slice",
+			builder: NewSelector[TestModel](db).Where(C("Id").In([]int{1, 2})),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE `id` IN (?,?);",
+				Args: []any{1, 2},
+			},
+		},
+		{
+			// 任意位置的切片都会被展开
+			name:    "in mixed",
+			builder: NewSelector[TestModel](db).Where(C("Id").In([]int{1, 2}, 3, [2]int{4, 5}, []byte("6"))),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE `id` IN (?,?,?,?,?,?);",
+				Args: []any{1, 2, 3, 4, 5, []byte("6")},
+			},
+		},
+		{
+			name:    "in single",
+			builder: NewSelector[TestModel](db).Where(C("Id").In(1)),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE `id` IN (?);",
+				Args: []any{1},
+			},
+		},
+		{
+			// 空切片恒为假
+			name:    "in empty",
+			builder: NewSelector[TestModel](db).Where(C("Id").In([]int{})),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `test_model` WHERE 1 = 0;",
+			},
+		},
+		{
+			name:    "not in",
+			builder: NewSelector[TestModel](db).Where(C("Id").NotIn([]int64{1, 2})),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE `id` NOT IN (?,?);",
+				Args: []any{int64(1), int64(2)},
+			},
+		},
+		{
+			// 空切片恒为真
+			name:    "not in empty",
+			builder: NewSelector[TestModel](db).Where(C("Id").NotIn(), C("Age").GT(18)),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE (1 = 1) AND (`age` > ?);",
+				Args: []any{18},
+			},
+		},
+		{
+			name:    "between",
+			builder: NewSelector[TestModel](db).Where(C("Age").Between(18, 30)),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE `age` BETWEEN ? AND ?;",
+				Args: []any{18, 30},
+			},
+		},
+		{
+			// 和列比较的值需要经过字段的 Serializer
+			name: "where serializer",
+			builder: NewSelector[SerializerModel](db).
+				Where(C("Created").GT(time.Unix(1696867200, 0)), C("Tags").Eq([]string{"a", "b"})),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `serializer_model` WHERE (`created` > ?) AND (`tags` = ?);",
+				Args: []any{int64(1696867200), "a,b"},
+			},
+		},
+		{
+			name: "in serializer",
+			builder: NewSelector[SerializerModel](db).
+				Where(C("Created").In(time.Unix(1696867200, 0), time.Time{}), C("Tags").Like("%a%")),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `serializer_model` WHERE (`created` IN (?,?)) AND (`tags` LIKE ?);",
+				Args: []any{int64(1696867200), int64(0), "%a%"},
+			},
+		},
+		{
+			name: "between serializer",
+			builder: NewSelector[SerializerModel](db).
+				Where(C("Created").Between(time.Unix(1696867200, 0), time.Unix(1696953600, 0))),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `serializer_model` WHERE `created` BETWEEN ? AND ?;",
+				Args: []any{int64(1696867200), int64(1696953600)},
+			},
+		},
+		{
+			name:    "where serializer invalid",
+			builder: NewSelector[SerializerModel](db).Where(C("Created").GT(int64(1))),
+			wantErr: errs.NewErrInvalidFieldValue(int64(1), "time.Time"),
+		},
+		{
+			name: "between and",
+			builder: NewSelector[TestModel](db).
+				Where(C("Age").Between(18, 30), C("FirstName").Like("D%")),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE (`age` BETWEEN ? AND ?) AND (`first_name` LIKE ?);",
+				Args: []any{18, 30, "D%"},
+			},
+		},
+		{
+			name: "is null",
+			builder: NewSelector[TestModel](db).
+				Where(C("LastName").IsNull().Or(C("FirstName").IsNotNull())),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `test_model` WHERE (`last_name` IS NULL) OR (`first_name` IS NOT NULL);",
+			},
+		},
+		{
+			name: "aggregate operators",
+			builder: NewSelector[TestModel](db).GroupBy(C("FirstName")).
+				Having(Count("Id").GTEq(2), Max("Age").In(18, 20), Avg("Age").Between(10, 20)),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `test_model` GROUP BY `first_name` " +
+					"HAVING ((COUNT(`id`) >= ?) AND (MAX(`age`) IN (?,?))) AND (AVG(`age`) BETWEEN ? AND ?);",
+				Args: []any{2, 18, 20, 10, 20},
+			},
+		},
+		{
+			name:    "aggregate alias",
+			builder: NewSelector[TestModel](db).Select(Max("Age").As("max_age")),
+			wantQuery: &Query{
+				SQL: "SELECT MAX(`age`) AS `max_age` FROM `test_model`;",
+			},
+		},
+		{
+			name:    "math select",
+			builder: NewSelector[TestModel](db).Select(C("Age").Add(1).As("next_age"), C("Id")),
+			wantQuery: &Query{
+				SQL:  "SELECT `age` + ? AS `next_age`,`id` FROM `test_model`;",
+				Args: []any{1},
+			},
+		},
+		{
+			// 嵌套
+			name:    "math nested",
+			builder: NewSelector[TestModel](db).Select(C("Age").Add(1).Multi(C("Id")).Div(2)),
+			wantQuery: &Query{
+				SQL:  "SELECT ((`age` + ?) * `id`) / ? FROM `test_model`;",
+				Args: []any{1, 2},
+			},
+		},
+		{
+			name: "math where",
+			builder: NewSelector[TestModel](db).
+				Where(C("Age").Sub(1).GT(18), C("Id").Eq(C("Age").Multi(2))),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE ((`age` - ?) > ?) AND (`id` = (`age` * ?));",
+				Args: []any{1, 18, 2},
+			},
+		},
+		{
+			name:    "math invalid column",
+			builder: NewSelector[TestModel](db).Where(C("Age").Add(C("Invalid")).GT(18)),
+			wantErr: errs.NewErrUnknownField("Invalid"),
+		},
+		{
+			name:    "order by",
+			builder: NewSelector[TestModel](db).OrderBy(Asc("Id"), Desc("Age")),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `test_model` ORDER BY `id` ASC,`age` DESC;",
+			},
+		},
+		{
+			name: "order by limit",
+			builder: NewSelector[TestModel](db).Where(C("Age").GT(18)).
+				OrderBy(C("Id").Desc()).Limit(10).Offset(20),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE `age` > ? ORDER BY `id` DESC LIMIT ? OFFSET ?;",
+				Args: []any{18, 10, 20},
+			},
+		},
+		{
+			name:    "order by invalid column",
+			builder: NewSelector[TestModel](db).OrderBy(Asc("Invalid")),
+			wantErr: errs.NewErrUnknownField("Invalid"),
+		},
+		{
+			name: "order by aggregate",
+			builder: NewSelector[TestModel](db).Select(C("FirstName")).
+				GroupBy(C("FirstName")).OrderBy(Count("Id").Desc(), Asc("FirstName")),
+			wantQuery: &Query{
+				SQL: "SELECT `first_name` FROM `test_model` GROUP BY `first_name` ORDER BY COUNT(`id`) DESC,`first_name` ASC;",
+			},
+		},
+		{
+			name: "order by alias",
+			builder: NewSelector[TestModel](db).
+				Select(C("FirstName"), Avg("Age").As("avg_age"), C("Id").As("my_id")).
+				GroupBy(C("FirstName")).OrderBy(Avg("Age").As("avg_age").Desc(), Asc("my_id")),
+			wantQuery: &Query{
+				SQL: "SELECT `first_name`,AVG(`age`) AS `avg_age`,`id` AS `my_id` FROM `test_model` " +
+					"GROUP BY `first_name` ORDER BY `avg_age` DESC,`my_id` ASC;",
+			},
+		},
+		{
+			// 别名也可以直接使用 Desc
+			name: "order by alias name",
+			builder: NewSelector[TestModel](db).
+				Select(Avg("Age").As("avg_age")).OrderBy(Desc("avg_age")),
+			wantQuery: &Query{
+				SQL: "SELECT AVG(`age`) AS `avg_age` FROM `test_model` ORDER BY `avg_age` DESC;",
+			},
+		},
+		{
+			// 多个聚合函数
+			name:    "multiple aggregate",
+			builder: NewSelector[TestModel](db).Select(Max("Age"), Min("Age"), C("FirstName")),
+			wantQuery: &Query{
+				SQL: "SELECT MAX(`age`),MIN(`age`),`first_name` FROM `test_model`;",
+			},
+		},
+		{
+			name:    "not",
+			builder: NewSelector[TestModel](db).Where(Not(C("Age").GT(18))),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE NOT (`age` > ?);",
+				Args: []any{18},
+			},
+		},
+		{
+			name: "not and",
+			builder: NewSelector[TestModel](db).
+				Where(Not(C("Age").GT(18)).And(C("Id").Eq(1))),
+			wantQuery: &Query{
+				SQL:  "SELECT * FROM `test_model` WHERE (NOT (`age` > ?)) AND (`id` = ?);",
+				Args: []any{18, 1},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -340,6 +594,101 @@ func TestSelector_Get(t *testing.T) {
 	}
 }
 
+func TestSelector_GetMulti(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	// query error
+	mock.ExpectQuery("SELECT .*").WillReturnError(errors.New("query error"))
+
+	// no rows
+	rows := mock.NewRows([]string{"id", "first_name", "age", "last_name"})
+	mock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+
+	// multiple rows
+	rows = mock.NewRows([]string{"id", "first_name", "age", "last_name"})
+	rows.AddRow([]byte("1"), []byte("Da"), []byte("18"), []byte("Ming"))
+	rows.AddRow([]byte("2"), []byte("Xiao"), []byte("20"), []byte("Hong"))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+
+	// invalid column
+	rows = mock.NewRows([]string{"id", "invalid"})
+	rows.AddRow([]byte("1"), []byte("Da"))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+
+	// rows error
+	rows = mock.NewRows([]string{"id", "first_name", "age", "last_name"})
+	rows.AddRow([]byte("1"), []byte("Da"), []byte("18"), []byte("Ming"))
+	rows.RowError(0, errors.New("rows error"))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+
+	db, err := OpenDB(mockDB)
+	require.NoError(t, err)
+	testCases := []struct {
+		name string
+		s    *Selector[TestModel]
+
+		wantRes []*TestModel
+		wantErr error
+	}{
+		{
+			name:    "invalid error",
+			s:       NewSelector[TestModel](db).Where(C("XXX").Eq("12")),
+			wantErr: errs.NewErrUnknownField("XXX"),
+		},
+		{
+			name:    "query error",
+			s:       NewSelector[TestModel](db).Where(C("Id").Eq("1")),
+			wantErr: errors.New("query error"),
+		},
+		{
+			// 没有数据返回空切片，而不是 ErrNoRows
+			name:    "no rows",
+			s:       NewSelector[TestModel](db).Where(C("Id").Eq("1")),
+			wantRes: []*TestModel{},
+		},
+		{
+			name: "multiple rows",
+			s:    NewSelector[TestModel](db),
+			wantRes: []*TestModel{
+				{
+					Id:        1,
+					FirstName: "Da",
+					Age:       18,
+					LastName:  &sql.NullString{Valid: true, String: "Ming"},
+				},
+				{
+					Id:        2,
+					FirstName: "Xiao",
+					Age:       20,
+					LastName:  &sql.NullString{Valid: true, String: "Hong"},
+				},
+			},
+		},
+		{
+			name:    "invalid column",
+			s:       NewSelector[TestModel](db),
+			wantErr: errs.NewErrUnknownColumn("invalid"),
+		},
+		{
+			name:    "rows error",
+			s:       NewSelector[TestModel](db),
+			wantErr: errors.New("rows error"),
+		},
+	}
+
+	for _, ts := range testCases {
+		t.Run(ts.name, func(t *testing.T) {
+			res, err := ts.s.GetMulti(context.Background())
+			assert.Equal(t, ts.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, ts.wantRes, res)
+		})
+	}
+}
+
 func TestSelector_Join(t *testing.T) {
 	sqlDB := mysqlDB()
 	defer sqlDB.Close()
@@ -377,6 +726,20 @@ func TestSelector_Join(t *testing.T) {
 				Args: nil,
 			},
 		},
+		{
+			// 结构体和结构体指针都可以作为表
+			name: "struct value table",
+			s: func() QueryBuilder {
+				t1 := TableOf(Order{}).As("t1")
+				t2 := TableOf(&OrderDetail{}).As("t2")
+				return NewSelector[Order](db).Select(t1.C("Id"), t2.C("ItemId")).
+					From(t1.Join(t2).On(t1.C("Id").Eq(t2.C("OrderId"))))
+			}(),
+			wantQuery: &Query{
+				SQL: "SELECT `t1`.`id`,`t2`.`item_id` FROM (`order` AS `t1` JOIN `order_detail` AS `t2` " +
+					"ON `t1`.`id` = `t2`.`order_id`);",
+			},
+		},
 		{
 			name: "using join",
 			s: func() QueryBuilder {
@@ -402,6 +765,19 @@ func TestSelector_Join(t *testing.T) {
 				SQL: "SELECT * FROM (`order` AS `t1` JOIN `order_detail` AS `t2` ON `t1`.`id` = `t2`.`order_id`);",
 			},
 		},
+		{
+			name: "join select columns",
+			s: func() QueryBuilder {
+				t1 := TableOf(&Order{}).As("t1")
+				t2 := TableOf(&OrderDetail{}).As("t2")
+				t3 := t1.Join(t2).On(t1.C("Id").Eq(t2.C("OrderId")))
+				return NewSelector[Order](db).Select(t1.C("Id"), t2.C("ItemId").As("item")).From(t3)
+			}(),
+			wantQuery: &Query{
+				SQL: "SELECT `t1`.`id`,`t2`.`item_id` AS `item` FROM " +
+					"(`order` AS `t1` JOIN `order_detail` AS `t2` ON `t1`.`id` = `t2`.`order_id`);",
+			},
+		},
 		{
 			name: "join table",
 			s: func() QueryBuilder {
@@ -446,6 +822,164 @@ func TestSelector_Join(t *testing.T) {
 	}
 }
 
+func TestSelector_Subquery(t *testing.T) {
+	sqlDB := mysqlDB()
+	defer sqlDB.Close()
+	db, err := OpenDB(sqlDB)
+	require.NoError(t, err)
+	type Order struct {
+		Id     int
+		UserId int
+	}
+
+	type OrderDetail struct {
+		OrderId int
+		ItemId  int
+	}
+
+	testCases := []struct {
+		name      string
+		s         QueryBuilder
+		wantQuery *Query
+		wantErr   error
+	}{
+		{
+			name: "from",
+			s: func() QueryBuilder {
+				sub := NewSelector[OrderDetail](db).Where(C("ItemId").GT(10)).AsSubquery("sub")
+				return NewSelector[Order](db).Select(sub.C("OrderId")).From(sub)
+			}(),
+			wantQuery: &Query{
+				SQL:  "SELECT `sub`.`order_id` FROM (SELECT * FROM `order_detail` WHERE `item_id` > ?) AS `sub`;",
+				Args: []any{10},
+			},
+		},
+		{
+			// 子查询指定了列，外层只能使用这些列
+			name: "from invalid column",
+			s: func() QueryBuilder {
+				sub := NewSelector[OrderDetail](db).Select(C("OrderId")).AsSubquery("sub")
+				return NewSelector[Order](db).Select(sub.C("ItemId")).From(sub)
+			}(),
+			wantErr: errs.NewErrUnknownField("ItemId"),
+		},
+		{
+			name: "from alias column",
+			s: func() QueryBuilder {
+				sub := NewSelector[OrderDetail](db).
+					Select(C("OrderId").As("oid"), Count("ItemId").As("cnt")).
+					GroupBy(C("OrderId")).AsSubquery("sub")
+				return NewSelector[Order](db).Select(sub.C("oid")).
+					From(sub).Where(sub.C("cnt").GT(2))
+			}(),
+			wantQuery: &Query{
+				SQL: "SELECT `sub`.`oid` FROM (SELECT `order_id` AS `oid`,COUNT(`item_id`) AS `cnt` " +
+					"FROM `order_detail` GROUP BY `order_id`) AS `sub` WHERE `sub`.`cnt` > ?;",
+				Args: []any{2},
+			},
+		},
+		{
+			// 参数顺序需要和占位符一致
+			name: "join",
+			s: func() QueryBuilder {
+				t1 := TableOf(&Order{}).As("t1")
+				sub := NewSelector[OrderDetail](db).Where(C("ItemId").GT(10)).AsSubquery("sub")
+				return NewSelector[Order](db).
+					Select(t1.C("Id"), sub.C("ItemId")).
+					From(t1.Join(sub).On(t1.C("Id").Eq(sub.C("OrderId")))).
+					Where(t1.C("UserId").Eq(3))
+			}(),
+			wantQuery: &Query{
+				SQL: "SELECT `t1`.`id`,`sub`.`item_id` FROM (`order` AS `t1` JOIN " +
+					"(SELECT * FROM `order_detail` WHERE `item_id` > ?) AS `sub` ON `t1`.`id` = `sub`.`order_id`) " +
+					"WHERE `t1`.`user_id` = ?;",
+				Args: []any{10, 3},
+			},
+		},
+		{
+			name: "subquery join",
+			s: func() QueryBuilder {
+				t1 := TableOf(&Order{}).As("t1")
+				sub := NewSelector[OrderDetail](db).AsSubquery("sub")
+				return NewSelector[Order](db).
+					From(sub.LeftJoin(t1).On(t1.C("Id").Eq(sub.C("OrderId"))))
+			}(),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM ((SELECT * FROM `order_detail`) AS `sub` LEFT JOIN `order` AS `t1` " +
+					"ON `t1`.`id` = `sub`.`order_id`);",
+			},
+		},
+		{
+			name: "in",
+			s: func() QueryBuilder {
+				sub := NewSelector[OrderDetail](db).Select(C("OrderId")).
+					Where(C("ItemId").Eq(12)).AsSubquery("sub")
+				return NewSelector[Order](db).Where(C("UserId").Eq(3), C("Id").In(sub))
+			}(),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `order` WHERE (`user_id` = ?) AND " +
+					"(`id` IN (SELECT `order_id` FROM `order_detail` WHERE `item_id` = ?));",
+				Args: []any{3, 12},
+			},
+		},
+		{
+			name: "not in",
+			s: func() QueryBuilder {
+				sub := NewSelector[OrderDetail](db).Select(C("OrderId")).AsSubquery("sub")
+				return NewSelector[Order](db).Where(C("Id").NotIn(sub))
+			}(),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `order` WHERE `id` NOT IN (SELECT `order_id` FROM `order_detail`);",
+			},
+		},
+		{
+			name: "exists",
+			s: func() QueryBuilder {
+				sub := NewSelector[OrderDetail](db).Where(C("ItemId").Eq(12)).AsSubquery("sub")
+				return NewSelector[Order](db).Where(Exists(sub), C("UserId").Eq(3))
+			}(),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `order` WHERE (EXISTS (SELECT * FROM `order_detail` WHERE `item_id` = ?)) " +
+					"AND (`user_id` = ?);",
+				Args: []any{12, 3},
+			},
+		},
+		{
+			name: "not exists",
+			s: func() QueryBuilder {
+				sub := NewSelector[OrderDetail](db).AsSubquery("sub")
+				return NewSelector[Order](db).Where(NotExists(sub))
+			}(),
+			wantQuery: &Query{
+				SQL: "SELECT * FROM `order` WHERE NOT EXISTS (SELECT * FROM `order_detail`);",
+			},
+		},
+		{
+			name: "inner error",
+			s: func() QueryBuilder {
+				sub := NewSelector[OrderDetail](db).Where(C("Invalid").Eq(12)).AsSubquery("sub")
+				return NewSelector[Order](db).Where(Exists(sub))
+			}(),
+			wantErr: errs.NewErrUnknownField("Invalid"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q, err := tc.s.Build()
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantQuery, q)
+			// Build 可能会被中间件多次调用，结果需要保持一致
+			q, err = tc.s.Build()
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantQuery, q)
+		})
+	}
+}
+
 // 在 orm 目录下执行
 // go test -bench=BenchmarkQuerier_Get -benchmem -benchtime=10000x
 func BenchmarkQuerier_Get(b *testing.B) {
@@ -520,3 +1054,152 @@ func mysqlDB() *sql.DB {
 	open.SetMaxIdleConns(2)
 	return open
 }
+
+func TestIgnoredField(t *testing.T) {
+	type CacheModel struct {
+		Id      int64
+		Name    string
+		Derived string `orm:"-"`
+		cache   map[string]string
+	}
+	db, err := OpenDB(mysqlDB())
+	require.NoError(t, err)
+	testCases := []struct {
+		name      string
+		q         QueryBuilder
+		wantQuery *Query
+		wantErr   error
+	}{
+		{
+			name: "insert",
+			q: NewInserter[CacheModel](db).Values(&CacheModel{
+				Id: 1, Name: "Tom", Derived: "derived", cache: map[string]string{},
+			}),
+			wantQuery: &Query{
+				SQL:  "INSERT INTO `cache_model`(`id`,`name`) VALUES (?,?);",
+				Args: []any{int64(1), "Tom"},
+			},
+		},
+		{
+			name:    "select unexported",
+			q:       NewSelector[CacheModel](db).Where(C("cache").Eq(1)),
+			wantErr: errs.NewErrIgnoredField("cache"),
+		},
+		{
+			name:    "select ignored",
+			q:       NewSelector[CacheModel](db).Select(C("Derived")),
+			wantErr: errs.NewErrIgnoredField("Derived"),
+		},
+		{
+			name:    "insert ignored column",
+			q:       NewInserter[CacheModel](db).Values(&CacheModel{}).Columns("Derived"),
+			wantErr: errs.NewErrIgnoredField("Derived"),
+		},
+		{
+			name:    "update ignored column",
+			q:       NewUpdater[CacheModel](db).Set(Assign("cache", nil)),
+			wantErr: errs.NewErrIgnoredField("cache"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q, err := tc.q.Build()
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantQuery, q)
+		})
+	}
+}
+
+func TestSelector_NamingStrategy(t *testing.T) {
+	type UserInfo struct {
+		UserID   int64
+		NickName string
+	}
+	db, err := OpenDB(mysqlDB(), WithNamingStrategy(model.PluralTable(model.SnakeCaseNaming)))
+	require.NoError(t, err)
+	q, err := NewSelector[UserInfo](db).Select(C("NickName")).Where(C("UserID").Eq(1)).Build()
+	require.NoError(t, err)
+	assert.Equal(t, &Query{
+		SQL:  "SELECT `nick_name` FROM `user_infos` WHERE `user_id` = ?;",
+		Args: []any{1},
+	}, q)
+
+	// 不管顺序如何，都不能覆盖用户指定的 Registry
+	_, err = OpenDB(mysqlDB(), WithRegistry(model.NewRegistry()), WithNamingStrategy(model.AsIsNaming))
+	assert.Equal(t, errs.ErrNamingStrategyWithRegistry, err)
+	_, err = OpenDB(mysqlDB(), WithNamingStrategy(model.AsIsNaming), WithRegistry(model.NewRegistry()))
+	assert.Equal(t, errs.ErrNamingStrategyWithRegistry, err)
+}
+
+func TestSelector_Mapping(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	strict, err := OpenDB(mockDB)
+	require.NoError(t, err)
+	lenient, err := OpenDB(mockDB, WithMappingMode(MappingLenient))
+	require.NoError(t, err)
+
+	newRows := func() *sqlmock.Rows {
+		// 表新增了 create_time 列
+		rows := mock.NewRows([]string{"ID", "first_name", "age", "last_name", "create_time"})
+		rows.AddRow([]byte("1"), []byte("Da"), []byte("18"), []byte("Ming"), []byte("100"))
+		return rows
+	}
+	wantVal := &TestModel{
+		Id:        1,
+		FirstName: "Da",
+		Age:       18,
+		LastName:  &sql.NullString{Valid: true, String: "Ming"},
+	}
+
+	testCases := []struct {
+		name    string
+		s       *Selector[TestModel]
+		wantVal *TestModel
+		wantErr error
+	}{
+		{
+			name:    "strict",
+			s:       NewSelector[TestModel](strict),
+			wantErr: errs.ErrTooManyReturnedColumns,
+		},
+		{
+			name:    "lenient db",
+			s:       NewSelector[TestModel](lenient),
+			wantVal: wantVal,
+		},
+		{
+			// 单个查询覆盖 DB 的设置
+			name:    "lenient query",
+			s:       NewSelector[TestModel](strict).Mapping(MappingLenient),
+			wantVal: wantVal,
+		},
+		{
+			name:    "strict query",
+			s:       NewSelector[TestModel](lenient).Mapping(MappingStrict),
+			wantErr: errs.ErrTooManyReturnedColumns,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mock.ExpectQuery("SELECT .*").WillReturnRows(newRows())
+			res, err := tc.s.Get(context.Background())
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantVal, res)
+		})
+	}
+
+	// 原生查询同样支持
+	mock.ExpectQuery("SELECT .*").WillReturnRows(newRows())
+	res, err := RawQuery[TestModel](strict, "SELECT * FROM `test_model`").
+		Mapping(MappingLenient).GetMulti(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []*TestModel{wantVal}, res)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,72 @@
+// create by chencanhua in 2023/9/23
+package orm
+
+// Subquery 子查询，通过 Selector.AsSubquery 构建
+// 可以用在 FROM、JOIN、IN 以及 EXISTS 中
+type Subquery struct {
+	// s 内层查询
+	s subqueryBuilder
+	// columns 内层查询 SELECT 的列，用于校验外层引用的列
+	columns []Selectable
+	// from 内层查询的 FROM，SELECT * 的时候用于解析列
+	from  TableReference
+	alias string
+}
+
+// subqueryBuilder 可以作为子查询的查询
+type subqueryBuilder interface {
+	buildRaw() (*Query, error)
+}
+
+func (Subquery) expr() {}
+
+func (Subquery) table() {}
+
+// C 引用子查询中的列
+// 子查询指定了列的时候，只能引用这些列或者它们的别名
+func (s Subquery) C(name string) Column {
+	return Column{
+		column: name,
+		table:  s,
+	}
+}
+
+func (s Subquery) Join(right TableReference) *JoinBuilder {
+	return &JoinBuilder{
+		left:  s,
+		right: right,
+		typ:   "JOIN",
+	}
+}
+
+func (s Subquery) LeftJoin(right TableReference) *JoinBuilder {
+	return &JoinBuilder{
+		left:  s,
+		right: right,
+		typ:   "LEFT JOIN",
+	}
+}
+
+func (s Subquery) RightJoin(right TableReference) *JoinBuilder {
+	return &JoinBuilder{
+		left:  s,
+		right: right,
+		typ:   "RIGHT JOIN",
+	}
+}
+
+// Exists EXISTS (子查询)
+func Exists(sub Subquery) Predicate {
+	return Predicate{
+		op:    opEXISTS,
+		right: sub,
+	}
+}
+
+// NotExists NOT EXISTS (子查询)
+func NotExists(sub Subquery) Predicate {
+	return Predicate{
+		op:    opNOTEXISTS,
+		right: sub,
+	}
+}
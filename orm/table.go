@@ -28,7 +28,6 @@ func (t Table) As(alias string) Table {
 func (t Table) C(goColumn string) Column {
 	return Column{
 		column: goColumn,
-		alias:  t.alias,
 		table:  t,
 	}
 }
@@ -9,8 +9,10 @@ import (
 // Querier 不同语句的各自实现
 // 使用泛型做类型的约束: 例如 SELECT 语句和 INSERT 语句
 type Querier[T any] interface {
+	// Get 返回第一行数据，没有数据时返回 ErrNoRows
 	Get(ctx context.Context) (*T, error)
-	GetMulti(ctx context.Context) (*[]T, error)
+	// GetMulti 返回全部数据，没有数据时返回空切片
+	GetMulti(ctx context.Context) ([]*T, error)
 }
 
 // Executor 执行角色，返回执行结果(insert、update、delete)
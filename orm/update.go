@@ -0,0 +1,163 @@
+// create by chencanhua in 2023/9/16
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"github.com/valyala/bytebufferpool"
+	"orm_framework/orm/internal/errs"
+	"orm_framework/orm/internal/valuer"
+	"reflect"
+)
+
+var (
+	_ QueryBuilder = &Updater[any]{}
+	_ Executor     = &Updater[any]{}
+)
+
+// Updater 用于构建 UPDATE 语句
+type Updater[T any] struct {
+	// val 通过 Update 指定的实体
+	val *T
+	updateBuilder
+	sess Session
+}
+
+func NewUpdater[T any](sess Session) *Updater[T] {
+	c := sess.getCore()
+	return &Updater[T]{
+		updateBuilder: updateBuilder{
+			builder: builder{
+				core: c,
+			},
+		},
+		sess: sess,
+	}
+}
+
+// Update 指定更新的实体
+// 没有调用 Set 的时候，会更新实体中全部非零值的字段
+func (u *Updater[T]) Update(val *T) *Updater[T] {
+	u.val = val
+	return u
+}
+
+// Set 指定更新的列
+// Assign("Age", 18) 使用指定的值，C("Age") 使用 Update 传入实体上的值
+func (u *Updater[T]) Set(assigns ...Assignable) *Updater[T] {
+	u.assigns = assigns
+	return u
+}
+
+func (u *Updater[T]) Where(ps ...Predicate) *Updater[T] {
+	u.where = ps
+	return u
+}
+
+func (u *Updater[T]) Build() (*Query, error) {
+	u.reset()
+	defer bytebufferpool.Put(u.buffer)
+	m, err := u.r.Get(new(T))
+	if err != nil {
+		return nil, err
+	}
+	u.model = m
+
+	var val valuer.Value
+	if u.val != nil {
+		val = u.Creator(u.val, m)
+	}
+
+	assigns := u.assigns
+	if len(assigns) == 0 && val != nil {
+		assigns, err = u.nonZeroAssigns(val)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(assigns) == 0 {
+		return nil, errs.ErrNoUpdatedColumns
+	}
+
+	u.writeString("UPDATE ")
+	u.quote(m.TableName)
+	u.writeString(" SET ")
+	for index, a := range assigns {
+		if index > 0 {
+			u.writeByte(',')
+		}
+		switch assign := a.(type) {
+		case Assignment:
+			if err = u.buildAssignment(assign); err != nil {
+				return nil, err
+			}
+		case Column:
+			if val == nil {
+				return nil, errs.ErrUpdateEntityRequired
+			}
+			if err = u.buildColumn(&Column{column: assign.column}); err != nil {
+				return nil, err
+			}
+			fd, err := u.model.FieldByName(assign.column)
+			if err != nil {
+				return nil, err
+			}
+			v, err := val.Field(assign.column)
+			if err != nil {
+				return nil, err
+			}
+			if v, err = fd.ColumnValue(v); err != nil {
+				return nil, err
+			}
+			u.writeString("=?")
+			u.addArgs(v)
+		default:
+			return nil, errs.NewErrUnsupportedAssignableType(a)
+		}
+	}
+
+	if len(u.where) > 0 {
+		u.writeString(" WHERE ")
+		if err = u.buildPredicates(u.where); err != nil {
+			return nil, err
+		}
+	}
+
+	return u.end(), nil
+}
+
+// nonZeroAssigns 从实体中挑选出非零值的字段，主键不会被更新
+func (u *Updater[T]) nonZeroAssigns(val valuer.Value) ([]Assignable, error) {
+	assigns := make([]Assignable, 0, len(u.model.Fields))
+	for _, fd := range u.model.Fields {
+		if fd.PrimaryKey {
+			continue
+		}
+		v, err := val.Field(fd.GoName)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil || reflect.ValueOf(v).IsZero() {
+			continue
+		}
+		assigns = append(assigns, C(fd.GoName))
+	}
+	return assigns, nil
+}
+
+func (u *Updater[T]) Exec(ctx context.Context) sql.Result {
+	qc := &QueryContext{
+		Type:    "UPDATE",
+		Builder: u,
+	}
+	result := exec(ctx, u.sess, u.core, qc)
+	if result.Result != nil {
+		return &Result{
+			res: result.Result.(sql.Result),
+			err: nil,
+		}
+	}
+	return &Result{
+		err: result.Err,
+	}
+}
@@ -0,0 +1,12 @@
+// create by chencanhua in 2023/9/16
+package orm
+
+type updaterBuilderAttribute struct {
+	assigns []Assignable
+	where   []Predicate
+}
+
+type updateBuilder struct {
+	builder
+	updaterBuilderAttribute
+}
@@ -0,0 +1,247 @@
+// create by chencanhua in 2023/9/16
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"orm_framework/orm/internal/errs"
+	"testing"
+)
+
+func TestUpdater_Build(t *testing.T) {
+	d := mysqlDB()
+	db, _ := OpenDB(d)
+	type PrimaryKeyModel struct {
+		Id   int64 `orm:"pk"`
+		Name string
+	}
+	type SerializerModel struct {
+		Id   int64    `orm:"pk"`
+		Tags []string `orm:"serializer=comma"`
+	}
+	testCases := []struct {
+		name      string
+		u         QueryBuilder
+		wantQuery *Query
+		wantErr   error
+	}{
+		{
+			// 啥也没指定
+			name:    "no columns",
+			u:       NewUpdater[TestModel](db),
+			wantErr: errs.ErrNoUpdatedColumns,
+		},
+		{
+			name: "assign",
+			u: NewUpdater[TestModel](db).
+				Set(Assign("FirstName", "Deng"), Assign("Age", 18)),
+			wantQuery: &Query{
+				SQL:  "UPDATE `test_model` SET `first_name`=?,`age`=?;",
+				Args: []any{"Deng", 18},
+			},
+		},
+		{
+			name: "where",
+			u: NewUpdater[TestModel](db).
+				Set(Assign("FirstName", "Deng")).
+				Where(C("Id").Eq(1), C("Age").GT(18)),
+			wantQuery: &Query{
+				SQL:  "UPDATE `test_model` SET `first_name`=? WHERE (`id` = ?) AND (`age` > ?);",
+				Args: []any{"Deng", 1, 18},
+			},
+		},
+		{
+			// 赋值也可以是表达式
+			name: "assign expression",
+			u: NewUpdater[TestModel](db).
+				Set(Assign("Age", Raw("`age`+?", 1)), Assign("FirstName", C("LastName"))),
+			wantQuery: &Query{
+				SQL:  "UPDATE `test_model` SET `age`=`age`+?,`first_name`=`last_name`;",
+				Args: []any{1},
+			},
+		},
+		{
+			name: "assign math",
+			u: NewUpdater[TestModel](db).
+				Set(Assign("Age", C("Age").Add(1).Multi(2))).Where(C("Id").Eq(1)),
+			wantQuery: &Query{
+				SQL:  "UPDATE `test_model` SET `age`=(`age` + ?) * ? WHERE `id` = ?;",
+				Args: []any{1, 2, 1},
+			},
+		},
+		{
+			name: "invalid assign column",
+			u: NewUpdater[TestModel](db).
+				Set(Assign("Invalid", "Deng")),
+			wantErr: errs.NewErrUnknownField("Invalid"),
+		},
+		{
+			name: "invalid where column",
+			u: NewUpdater[TestModel](db).
+				Set(Assign("FirstName", "Deng")).
+				Where(C("Invalid").Eq(1)),
+			wantErr: errs.NewErrUnknownField("Invalid"),
+		},
+		{
+			// 使用 C 但是没有指定实体
+			name:    "column without entity",
+			u:       NewUpdater[TestModel](db).Set(C("FirstName")),
+			wantErr: errs.ErrUpdateEntityRequired,
+		},
+		{
+			// 只更新指定的列，值从实体上取
+			name: "entity columns",
+			u: NewUpdater[TestModel](db).Update(&TestModel{
+				Id:        1,
+				FirstName: "Deng",
+				Age:       18,
+			}).Set(C("FirstName"), C("Age")).Where(C("Id").Eq(1)),
+			wantQuery: &Query{
+				SQL:  "UPDATE `test_model` SET `first_name`=?,`age`=? WHERE `id` = ?;",
+				Args: []any{"Deng", int8(18), 1},
+			},
+		},
+		{
+			// 混合使用
+			name: "entity columns and assign",
+			u: NewUpdater[TestModel](db).Update(&TestModel{
+				FirstName: "Deng",
+			}).Set(C("FirstName"), Assign("Age", 20)),
+			wantQuery: &Query{
+				SQL:  "UPDATE `test_model` SET `first_name`=?,`age`=?;",
+				Args: []any{"Deng", 20},
+			},
+		},
+		{
+			name: "invalid entity column",
+			u: NewUpdater[TestModel](db).Update(&TestModel{}).
+				Set(C("Invalid")),
+			wantErr: errs.NewErrUnknownField("Invalid"),
+		},
+		{
+			// 没有 Set 的时候更新全部非零值
+			name: "entity non zero",
+			u: NewUpdater[TestModel](db).Update(&TestModel{
+				FirstName: "Deng",
+				LastName:  &sql.NullString{String: "Ming", Valid: true},
+			}).Where(C("Id").Eq(1)),
+			wantQuery: &Query{
+				SQL:  "UPDATE `test_model` SET `first_name`=?,`last_name`=? WHERE `id` = ?;",
+				Args: []any{"Deng", &sql.NullString{String: "Ming", Valid: true}, 1},
+			},
+		},
+		{
+			// 主键不会被更新
+			name: "entity primary key",
+			u: NewUpdater[PrimaryKeyModel](db).Update(&PrimaryKeyModel{
+				Id:   1,
+				Name: "Deng",
+			}).Where(C("Id").Eq(1)),
+			wantQuery: &Query{
+				SQL:  "UPDATE `primary_key_model` SET `name`=? WHERE `id` = ?;",
+				Args: []any{"Deng", 1},
+			},
+		},
+		{
+			// 直接赋值同样需要序列化
+			name: "assign serializer",
+			u: NewUpdater[SerializerModel](db).
+				Set(Assign("Tags", []string{"a", "b"})).Where(C("Id").Eq(1)),
+			wantQuery: &Query{
+				SQL:  "UPDATE `serializer_model` SET `tags`=? WHERE `id` = ?;",
+				Args: []any{"a,b", 1},
+			},
+		},
+		{
+			// 表达式不经过 Serializer
+			name: "assign serializer expression",
+			u: NewUpdater[SerializerModel](db).
+				Set(Assign("Tags", Raw("CONCAT(`tags`, ?)", ",c"))).Where(C("Id").Eq(1)),
+			wantQuery: &Query{
+				SQL:  "UPDATE `serializer_model` SET `tags`=CONCAT(`tags`, ?) WHERE `id` = ?;",
+				Args: []any{",c", 1},
+			},
+		},
+		{
+			name: "entity serializer",
+			u: NewUpdater[SerializerModel](db).Update(&SerializerModel{
+				Tags: []string{"a", "b"},
+			}).Set(C("Tags")).Where(C("Id").Eq(1)),
+			wantQuery: &Query{
+				SQL:  "UPDATE `serializer_model` SET `tags`=? WHERE `id` = ?;",
+				Args: []any{"a,b", 1},
+			},
+		},
+		{
+			// 全部都是零值
+			name:    "entity all zero",
+			u:       NewUpdater[TestModel](db).Update(&TestModel{}),
+			wantErr: errs.ErrNoUpdatedColumns,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := tc.u.Build()
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantQuery, query)
+		})
+	}
+}
+
+func TestUpdater_Exec(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	db, err := OpenDB(mockDB)
+	require.NoError(t, err)
+	testCases := []struct {
+		name     string
+		u        *Updater[TestModel]
+		wantErr  error
+		affected int64
+	}{
+		{
+			name: "query error",
+			u: NewUpdater[TestModel](db).
+				Set(Assign("Invalid", 1)),
+			wantErr: errs.NewErrUnknownField("Invalid"),
+		},
+		{
+			name: "db error",
+			u: func() *Updater[TestModel] {
+				mock.ExpectExec("UPDATE .*").
+					WillReturnError(errors.New("db error"))
+				return NewUpdater[TestModel](db).Set(Assign("Age", 18))
+			}(),
+			wantErr: errors.New("db error"),
+		},
+		{
+			name: "exec",
+			u: func() *Updater[TestModel] {
+				res := driver.RowsAffected(2)
+				mock.ExpectExec("UPDATE .*").WillReturnResult(res)
+				return NewUpdater[TestModel](db).Set(Assign("Age", 18))
+			}(),
+			affected: 2,
+		},
+	}
+	for _, ts := range testCases {
+		t.Run(ts.name, func(t *testing.T) {
+			res := ts.u.Exec(context.Background())
+			affected, err := res.RowsAffected()
+			assert.Equal(t, ts.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, ts.affected, affected)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+// create by chencanhua in 2023/10/15
+package orm
+
+import (
+	"database/sql"
+	"orm_framework/orm/internal/valuer"
+	"orm_framework/orm/model"
+	"reflect"
+)
+
+// Valuer 结构体实例的抽象，orm_gen 生成的 Value 实现了这个接口
+type Valuer = valuer.Value
+
+// RegisterValue 注册 T 的 Value，一般由 orm_gen 生成的代码在 init 中调用
+// 注册之后没有通过 WithReflectValue 或者 WithUnsafeValue 指定 Value 的 DB 处理 T 的时候都会使用它
+// 生成的 Value 按照默认的命名策略和标签确定列名，通过 WithTypeSerializer 序列化的字段需要在标签中声明 serializer
+func RegisterValue[T any](creator func(entity *T, meta *model.Model) Valuer) {
+	valuer.Register(reflect.TypeOf((*T)(nil)), func(entity any, meta *model.Model) valuer.Value {
+		return creator(entity.(*T), meta)
+	})
+}
+
+// ScanColumns 供生成的 Value 实现 SetColumns
+// dest 中已经设置的列直接 Scan 到对应的地址，其余的列通过 meta 查找字段之后使用 address 返回的地址
+func ScanColumns(rows *sql.Rows, cs []string, dest []any, meta *model.Model,
+	mapping MappingMode, address func(fd *model.Field) any) error {
+	return valuer.ScanColumns(rows, cs, dest, meta, mapping, address)
+}
+
+// SetValue 将 val 转换为字段的类型之后设置到 ptr 指向的字段，供生成的 Value 实现 SetField
+func SetValue(ptr any, val any) error {
+	return valuer.SetValue(ptr, val)
+}
@@ -0,0 +1,64 @@
+// create by chencanhua in 2023/10/15
+package orm
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"orm_framework/orm/internal/valuer"
+	"orm_framework/orm/model"
+	"testing"
+)
+
+type registeredModel struct {
+	Id int64
+}
+
+type registeredValue struct {
+	Valuer
+}
+
+func TestRegisterValue(t *testing.T) {
+	RegisterValue[registeredModel](func(entity *registeredModel, meta *model.Model) Valuer {
+		return registeredValue{Valuer: valuer.NewUnsafeValue(entity, meta)}
+	})
+
+	testCases := []struct {
+		name   string
+		opts   []DBOptions
+		entity any
+		want   any
+	}{
+		{
+			name:   "registered",
+			entity: &registeredModel{},
+			want:   registeredValue{},
+		},
+		{
+			// 用户指定的 Value 优先于注册的 Value
+			name:   "registered reflect",
+			opts:   []DBOptions{WithReflectValue()},
+			entity: &registeredModel{},
+			want:   valuer.NewReflectValue(&registeredModel{}, &model.Model{}),
+		},
+		{
+			name:   "registered unsafe",
+			opts:   []DBOptions{WithUnsafeValue()},
+			entity: &registeredModel{},
+			want:   valuer.NewUnsafeValue(&registeredModel{}, &model.Model{}),
+		},
+		{
+			name:   "unregistered",
+			entity: &TestModel{},
+			want:   valuer.NewUnsafeValue(&TestModel{}, &model.Model{}),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, err := OpenDB(mysqlDB(), tc.opts...)
+			require.NoError(t, err)
+			m, err := db.r.Get(tc.entity)
+			require.NoError(t, err)
+			assert.IsType(t, tc.want, db.Creator(tc.entity, m))
+		})
+	}
+}